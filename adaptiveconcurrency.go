@@ -0,0 +1,126 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+var ErrConcurrencyLimitExceeded = errors.New("concurrency limit exceeded")
+
+type ConcurrencyLimit interface {
+	Limit() int
+	Update(rtt time.Duration, inFlight int, dropped bool) int
+}
+
+func AIMDConcurrencyLimit(
+	initialLimit int,
+	minLimit int,
+	maxLimit int,
+	backoffRatio float64,
+	timeout time.Duration) ConcurrencyLimit {
+
+	return internal.NewAimdLimit(initialLimit, minLimit, maxLimit, backoffRatio, timeout)
+}
+
+func VegasConcurrencyLimit(initialLimit int, maxLimit int, smoothing float64) ConcurrencyLimit {
+	return internal.NewVegasLimit(initialLimit, maxLimit, smoothing)
+}
+
+func Gradient2ConcurrencyLimit(
+	initialLimit int,
+	minLimit int,
+	maxLimit int,
+	tolerance float64,
+	longWindow int) ConcurrencyLimit {
+
+	return internal.NewGradient2Limit(initialLimit, minLimit, maxLimit, tolerance, longWindow)
+}
+
+type AdaptiveConcurrencyLimiter[S any, T any] struct {
+	m        sync.Mutex
+	limit    ConcurrencyLimit
+	current  int
+	inFlight int
+	p        func(T, error) bool
+	counters policyCounters
+	gate     policyGate
+}
+
+func NewAdaptiveConcurrencyLimiter[S any, T any](
+	limit ConcurrencyLimit,
+	p func(T, error) bool) *AdaptiveConcurrencyLimiter[S, T] {
+
+	return &AdaptiveConcurrencyLimiter[S, T]{limit: limit, current: limit.Limit(), p: p}
+}
+
+func (l *AdaptiveConcurrencyLimiter[S, T]) Limit() int {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.current
+}
+
+func (l *AdaptiveConcurrencyLimiter[S, T]) InFlight() int {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.inFlight
+}
+
+func (l *AdaptiveConcurrencyLimiter[S, T]) Stats() Stats {
+	return l.counters.snapshot()
+}
+
+func (l *AdaptiveConcurrencyLimiter[S, T]) Policy() Policy[S, T] {
+	return gated(&l.gate, &l.counters, func(ctx context.Context, f func(context.Context, S) (T, error), s S) (result T, err error) {
+		inFlight, ok := l.tryAcquire()
+		if !ok {
+			l.counters.reject()
+			return result, ErrConcurrencyLimitExceeded
+		}
+		start := time.Now()
+		// A panicking call is treated as dropped, the slot is given back either way
+		dropped := true
+		defer func() {
+			rtt := time.Since(start)
+			l.m.Lock()
+			defer l.m.Unlock()
+			l.inFlight--
+			// A call cancelled by the caller says nothing about the downstream capacity
+			if ctx.Err() == nil {
+				l.current = l.limit.Update(rtt, inFlight, dropped)
+			}
+		}()
+		result, err = countedCall(&l.counters, ctx, f, s)
+		dropped = !l.p(result, err)
+		return result, err
+	})
+}
+
+func (l *AdaptiveConcurrencyLimiter[S, T]) Drain(ctx context.Context) error {
+	return l.gate.drain(ctx)
+}
+
+func (l *AdaptiveConcurrencyLimiter[S, T]) Close() {
+	l.gate.close()
+}
+
+func (l *AdaptiveConcurrencyLimiter[S, T]) tryAcquire() (int, bool) {
+	l.m.Lock()
+	defer l.m.Unlock()
+	if l.inFlight >= l.current {
+		return 0, false
+	}
+	l.inFlight++
+	return l.inFlight, true
+}
+
+func (pf PolicyFunc[S, T]) AdaptiveConcurrency(limit ConcurrencyLimit, p func(T, error) bool) PolicyFunc[S, T] {
+	return NewAdaptiveConcurrencyPolicy[S, T](limit, p).Bind(pf)
+}
+
+func NewAdaptiveConcurrencyPolicy[S any, T any](limit ConcurrencyLimit, p func(T, error) bool) Policy[S, T] {
+	return NewAdaptiveConcurrencyLimiter[S, T](limit, p).Policy()
+}
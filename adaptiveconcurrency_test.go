@@ -0,0 +1,85 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	t.Run("should reject execution when in-flight calls reach current limit", func(t *testing.T) {
+		// Arrange
+		limiter := NewAdaptiveConcurrencyLimiter[string, int](
+			AIMDConcurrencyLimit(1, 1, 10, 0.5, time.Second),
+			RejectOnError[int])
+		policy := limiter.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+
+		// Act
+		_, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "bar")
+		inFlight := limiter.InFlight()
+		close(barrier)
+		<-done
+
+		// Assert
+		if !errors.Is(err, ErrConcurrencyLimitExceeded) || inFlight != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should adjust limit according to outcomes", func(t *testing.T) {
+		// Arrange
+		limiter := NewAdaptiveConcurrencyLimiter[string, int](
+			AIMDConcurrencyLimit(4, 1, 10, 0.5, time.Second),
+			RejectOnError[int])
+		policy := limiter.Policy()
+
+		// Act
+		policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return 0, errSomethingWentWrong
+		}, "foo")
+
+		// Assert
+		if limiter.Limit() != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should give slot back when call panics", func(t *testing.T) {
+		// Arrange
+		limiter := NewAdaptiveConcurrencyLimiter[string, int](
+			AIMDConcurrencyLimit(1, 1, 10, 0.5, time.Second),
+			RejectOnError[int])
+		policy := Compose(NewPanicFallbackPolicy[string, int](IdentityFallback[int]), limiter.Policy())
+
+		// Act
+		_, panicErr := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			panic(errSomethingWentWrong)
+		}, "foo")
+		result, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "foo")
+
+		// Assert
+		if panicErr != errSomethingWentWrong || limiter.InFlight() != 0 {
+			t.Fail()
+		}
+		if result != 3 || err != nil {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+type AdaptiveTimeoutOptions struct {
+	// Percentile of observed latencies, must be in range (0, 1]. Successful calls
+	// are sampled with their latency, calls cut off by the timeout with at least
+	// the timeout they were given.
+	Percentile float64
+	// Headroom on top of the percentile, defaults to 1
+	Multiplier float64
+	MinTimeout time.Duration
+	// Zero means no upper bound
+	MaxTimeout time.Duration
+	// Timeout used until enough samples are collected
+	FallbackTimeout time.Duration
+	// Number of the most recent samples the percentile is computed over, defaults to 100
+	Window int
+	// Defaults to Window
+	MinSamples int
+	Kind       TimeoutPolicyKind
+	// Measures latencies of sampled calls, defaults to the system clock. It does
+	// not affect enforcement of the timeout, which relies on context deadlines
+	// and therefore on the system clock.
+	TimeProvider TimeProvider
+}
+
+type latencyWindow interface {
+	Add(time.Duration)
+	Len() int
+	Percentile(float64) time.Duration
+}
+
+type AdaptiveTimeout[S any, T any] struct {
+	options AdaptiveTimeoutOptions
+	window  latencyWindow
+	policy  Policy[S, T]
+}
+
+func NewAdaptiveTimeoutPolicy[S any, T any](options AdaptiveTimeoutOptions) Policy[S, T] {
+	return NewAdaptiveTimeout[S, T](options).Policy()
+}
+
+func NewAdaptiveTimeout[S any, T any](options AdaptiveTimeoutOptions) *AdaptiveTimeout[S, T] {
+	if options.Percentile <= 0 || options.Percentile > 1 {
+		panic("percentile must be in range (0, 1]")
+	}
+	if options.Multiplier == 0 {
+		options.Multiplier = 1
+	}
+	if options.Multiplier < 0 {
+		panic("multiplier must be > 0")
+	}
+	if options.MinTimeout < 0 || options.MaxTimeout < 0 {
+		panic("timeout bounds must be >= 0")
+	}
+	if options.MaxTimeout > 0 && options.MinTimeout > options.MaxTimeout {
+		panic("min timeout must be <= max timeout")
+	}
+	if options.Window == 0 {
+		options.Window = 100
+	}
+	if options.MinSamples == 0 {
+		options.MinSamples = options.Window
+	}
+	if options.MinSamples < 0 || options.MinSamples > options.Window {
+		panic("min samples must be in range [1, window]")
+	}
+	if options.TimeProvider == nil {
+		options.TimeProvider = internal.DefaultTimeProvider
+	}
+	at := &AdaptiveTimeout[S, T]{options: options, window: internal.NewLatencyWindow(options.Window)}
+	at.policy = NewDynamicTimeoutPolicy[S, T](func(context.Context, S) time.Duration {
+		return at.Timeout()
+	}, options.Kind)
+	return at
+}
+
+// Timeout is the timeout the next call gets
+func (at *AdaptiveTimeout[S, T]) Timeout() time.Duration {
+	if at.window.Len() < at.options.MinSamples {
+		return at.options.FallbackTimeout
+	}
+	timeout := time.Duration(float64(at.window.Percentile(at.options.Percentile)) * at.options.Multiplier)
+	if timeout < at.options.MinTimeout {
+		return at.options.MinTimeout
+	}
+	if at.options.MaxTimeout > 0 && timeout > at.options.MaxTimeout {
+		return at.options.MaxTimeout
+	}
+	return timeout
+}
+
+func (at *AdaptiveTimeout[S, T]) Policy() Policy[S, T] {
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		start := at.options.TimeProvider.UtcNow()
+		result, err := at.policy(ctx, f, s)
+		latency := at.options.TimeProvider.UtcNow().Sub(start)
+		if err == nil {
+			at.window.Add(latency)
+			return result, err
+		}
+		// A call cut off by the timeout would have taken at least as long as the
+		// timeout. Leaving it out would let the percentile only go down.
+		// Other failures tend to be fast and say little about the latency.
+		var timeoutErr *TimeoutError
+		if errors.As(err, &timeoutErr) && !timeoutErr.ParentDeadline {
+			at.window.Add(max(latency, timeoutErr.Timeout))
+		}
+		return result, err
+	}
+}
+
+func (pf PolicyFunc[S, T]) AdaptiveTimeout(options AdaptiveTimeoutOptions) PolicyFunc[S, T] {
+	return NewAdaptiveTimeoutPolicy[S, T](options).Bind(pf)
+}
@@ -0,0 +1,158 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+func TestAdaptiveTimeout(t *testing.T) {
+	sleep := func(timeProvider interface{ Advance(time.Duration) time.Time }) func(context.Context, time.Duration) (int, error) {
+		return func(ctx context.Context, d time.Duration) (int, error) {
+			timeProvider.Advance(d)
+			return 0, nil
+		}
+	}
+
+	t.Run("should use fallback timeout until enough samples are collected", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		timeout := NewAdaptiveTimeout[time.Duration, int](AdaptiveTimeoutOptions{
+			Percentile:      0.5,
+			FallbackTimeout: 1 * time.Second,
+			Window:          10,
+			MinSamples:      3,
+			TimeProvider:    timeProvider,
+		})
+		policy := timeout.Policy()
+
+		// Act
+		policy(context.Background(), sleep(timeProvider), 10*time.Millisecond)
+		policy(context.Background(), sleep(timeProvider), 20*time.Millisecond)
+		before := timeout.Timeout()
+		policy(context.Background(), sleep(timeProvider), 30*time.Millisecond)
+		after := timeout.Timeout()
+
+		// Assert
+		if before != 1*time.Second || after != 20*time.Millisecond {
+			t.Fail()
+		}
+	})
+
+	t.Run("should scale percentile by multiplier and clamp it", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		options := AdaptiveTimeoutOptions{
+			Percentile:   1,
+			Multiplier:   2,
+			MinTimeout:   50 * time.Millisecond,
+			MaxTimeout:   300 * time.Millisecond,
+			Window:       1,
+			TimeProvider: timeProvider,
+		}
+		timeout := NewAdaptiveTimeout[time.Duration, int](options)
+		policy := timeout.Policy()
+		var timeouts []time.Duration
+
+		// Act
+		for _, latency := range []time.Duration{10 * time.Millisecond, 100 * time.Millisecond, 1 * time.Second} {
+			policy(context.Background(), sleep(timeProvider), latency)
+			timeouts = append(timeouts, timeout.Timeout())
+		}
+
+		// Assert
+		if timeouts[0] != 50*time.Millisecond || timeouts[1] != 200*time.Millisecond || timeouts[2] != 300*time.Millisecond {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not sample failed calls", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		timeout := NewAdaptiveTimeout[time.Duration, int](AdaptiveTimeoutOptions{
+			Percentile:      1,
+			FallbackTimeout: 1 * time.Second,
+			Window:          1,
+			TimeProvider:    timeProvider,
+		})
+
+		// Act
+		_, err := timeout.Policy()(
+			context.Background(),
+			func(ctx context.Context, d time.Duration) (int, error) {
+				timeProvider.Advance(d)
+				return 0, errSomethingWentWrong
+			},
+			10*time.Millisecond,
+		)
+
+		// Assert
+		if !errors.Is(err, errSomethingWentWrong) || timeout.Timeout() != 1*time.Second {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject call that exceeds adapted timeout", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		timeout := NewAdaptiveTimeout[time.Duration, int](AdaptiveTimeoutOptions{
+			Percentile:   1,
+			Window:       1,
+			TimeProvider: timeProvider,
+		})
+		policy := timeout.Policy()
+		policy(context.Background(), sleep(timeProvider), 10*time.Millisecond)
+
+		// Act
+		_, err := policy(
+			context.Background(),
+			func(ctx context.Context, d time.Duration) (int, error) {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			},
+			0,
+		)
+
+		// Assert
+		var timeoutErr *TimeoutError
+		if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 10*time.Millisecond {
+			t.Fail()
+		}
+	})
+
+	t.Run("should hold timeout steady against steady latency distribution", func(t *testing.T) {
+		// Arrange
+		timeout := NewAdaptiveTimeout[time.Duration, int](AdaptiveTimeoutOptions{
+			Percentile: 0.5,
+			Window:     20,
+		})
+		policy := timeout.Policy()
+		f := func(ctx context.Context, latency time.Duration) (int, error) {
+			select {
+			case <-time.After(latency):
+				return 0, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+		var successes int
+
+		// Act
+		for round := 0; round < 5; round++ {
+			successes = 0
+			for i := 1; i <= 20; i++ {
+				if _, err := policy(context.Background(), f, time.Duration(i)*time.Millisecond); err == nil {
+					successes++
+				}
+			}
+		}
+
+		// Assert
+		if timeout.Timeout() < 9*time.Millisecond || successes < 8 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package resilience
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type DeadlineBudgetOptions struct {
+	// Number of attempts the budget is split across. Each attempt gets the
+	// remaining budget divided by the number of remaining attempts.
+	Attempts int
+	// When positive, each attempt gets this fraction of the remaining budget instead
+	Fraction float64
+	// Fraction of the caller's remaining time kept aside for the policies outside
+	// of the budget, such as fallbacks
+	Reserve float64
+}
+
+type budgetKey struct{}
+
+type deadlineBudget struct {
+	m            sync.Mutex
+	deadline     time.Time
+	attemptsLeft int
+	fraction     float64
+}
+
+// Hands out the share of the remaining budget to the next attempt
+func (b *deadlineBudget) nextAttempt() time.Duration {
+	b.m.Lock()
+	defer b.m.Unlock()
+	remaining := time.Until(b.deadline)
+	if remaining <= 0 {
+		// Zero would mean no timeout
+		return time.Nanosecond
+	}
+	if b.fraction > 0 {
+		return max(time.Duration(float64(remaining)*b.fraction), time.Nanosecond)
+	}
+	share := remaining / time.Duration(b.attemptsLeft)
+	if b.attemptsLeft > 1 {
+		b.attemptsLeft--
+	}
+	return share
+}
+
+func (pf PolicyFunc[S, T]) DeadlineBudget(options DeadlineBudgetOptions) PolicyFunc[S, T] {
+	return NewDeadlineBudgetPolicy[S, T](options).Bind(pf)
+}
+
+// NewDeadlineBudgetPolicy splits the time left until the deadline of the caller's
+// context between attempts, so it is meant to wrap a retry policy. Timeout policies
+// inside of it take their share through BudgetTimeout. Calls without deadline
+// are passed through.
+func NewDeadlineBudgetPolicy[S any, T any](options DeadlineBudgetOptions) Policy[S, T] {
+	if options.Fraction < 0 || options.Fraction > 1 {
+		panic("fraction must be in range [0, 1]")
+	}
+	if options.Fraction == 0 && options.Attempts <= 0 {
+		panic("attempts must be > 0")
+	}
+	if options.Reserve < 0 || options.Reserve >= 1 {
+		panic("reserve must be in range [0, 1)")
+	}
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return f(ctx, s)
+		}
+		reserve := time.Duration(float64(time.Until(deadline)) * options.Reserve)
+		budget := &deadlineBudget{
+			deadline:     deadline.Add(-reserve),
+			attemptsLeft: options.Attempts,
+			fraction:     options.Fraction,
+		}
+		budgetCtx, cancel := context.WithDeadline(ctx, budget.deadline)
+		defer cancel()
+		return f(context.WithValue(budgetCtx, budgetKey{}, budget), s)
+	}
+}
+
+// BudgetTimeout caps the timeout to the share of the deadline budget the attempt
+// is entitled to. Each call consumes an attempt. When the context carries no
+// budget, the timeout is left as is. A nil timeout means no timeout of its own.
+func BudgetTimeout[S any](timeout TimeoutFunc[S]) TimeoutFunc[S] {
+	return func(ctx context.Context, s S) time.Duration {
+		var own time.Duration
+		if timeout != nil {
+			own = timeout(ctx, s)
+		}
+		budget, ok := ctx.Value(budgetKey{}).(*deadlineBudget)
+		if !ok {
+			return own
+		}
+		share := budget.nextAttempt()
+		if own > 0 && own < share {
+			return own
+		}
+		return share
+	}
+}
@@ -0,0 +1,111 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeadlineBudget(t *testing.T) {
+	// Collects timeouts the attempts were given
+	attempts := func(timeouts *[]time.Duration) Policy[string, int] {
+		return Pipeline(
+			NewRetryPolicy[string, int](RetryOnError[int](10)),
+			NewDynamicTimeoutPolicy[string, int](func(ctx context.Context, s string) time.Duration {
+				timeout := BudgetTimeout[string](nil)(ctx, s)
+				*timeouts = append(*timeouts, timeout)
+				return timeout
+			}, OptimisticTimeoutPolicy),
+		)
+	}
+	blocked := func(ctx context.Context, s string) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+	near := func(actual time.Duration, expected time.Duration) bool {
+		return actual <= expected && actual > expected-20*time.Millisecond
+	}
+
+	t.Run("should split remaining budget between remaining attempts", func(t *testing.T) {
+		// Arrange
+		var timeouts []time.Duration
+		ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+		defer cancel()
+		policy := Compose(
+			NewDeadlineBudgetPolicy[string, int](DeadlineBudgetOptions{Attempts: 3, Reserve: 0.25}),
+			attempts(&timeouts),
+		)
+
+		// Act
+		_, err := policy(ctx, blocked, "foo")
+		parentErr := ctx.Err()
+
+		// Assert
+		if !errors.Is(err, context.DeadlineExceeded) || parentErr != nil {
+			t.Fail()
+		}
+		if len(timeouts) < 3 || !near(timeouts[0], 100*time.Millisecond) ||
+			!near(timeouts[1], 100*time.Millisecond) || !near(timeouts[2], 100*time.Millisecond) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should give each attempt a fraction of remaining budget", func(t *testing.T) {
+		// Arrange
+		var timeouts []time.Duration
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		policy := Compose(
+			NewDeadlineBudgetPolicy[string, int](DeadlineBudgetOptions{Fraction: 0.5}),
+			attempts(&timeouts),
+		)
+
+		// Act
+		policy(ctx, blocked, "foo")
+
+		// Assert
+		if len(timeouts) < 2 || !near(timeouts[0], 100*time.Millisecond) || !near(timeouts[1], 50*time.Millisecond) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should keep own timeout when it is shorter than the share", func(t *testing.T) {
+		// Arrange
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Hour)
+		defer cancel()
+		var timeout time.Duration
+		policy := NewDeadlineBudgetPolicy[string, int](DeadlineBudgetOptions{Attempts: 2})
+
+		// Act
+		policy(ctx, func(ctx context.Context, s string) (int, error) {
+			timeout = BudgetTimeout(FixedTimeout[string](10*time.Millisecond))(ctx, s)
+			return 0, nil
+		}, "foo")
+
+		// Assert
+		if timeout != 10*time.Millisecond {
+			t.Fail()
+		}
+	})
+
+	t.Run("should pass call through when context has no deadline", func(t *testing.T) {
+		// Arrange
+		var timeout time.Duration
+		policy := NewDeadlineBudgetPolicy[string, int](DeadlineBudgetOptions{Attempts: 2})
+
+		// Act
+		policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			if _, ok := ctx.Deadline(); ok {
+				t.Fail()
+			}
+			timeout = BudgetTimeout(FixedTimeout[string](10*time.Millisecond))(ctx, s)
+			return 0, nil
+		}, "foo")
+
+		// Assert
+		if timeout != 10*time.Millisecond {
+			t.Fail()
+		}
+	})
+}
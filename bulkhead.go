@@ -3,11 +3,125 @@ package resilience
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/mapogolions/resilience/internal"
 )
 
 var ErrBulkheadRejected = errors.New("bulkhead rejected")
+var ErrBulkheadQueueTimeout = fmt.Errorf("%w: queue wait timed out", ErrBulkheadRejected)
+var ErrBulkheadShed = fmt.Errorf("%w: shed by queue management", ErrBulkheadRejected)
+
+// Criticality tiers that can be used as preset priorities
+const (
+	CriticalitySheddable = 0
+	CriticalityDefault   = 100
+	CriticalityCritical  = 200
+)
+
+type priorityKey struct{}
+
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func PriorityFromContext(ctx context.Context) int {
+	if priority, ok := ctx.Value(priorityKey{}).(int); ok {
+		return priority
+	}
+	return CriticalityDefault
+}
+
+func ContextPriority[S any](ctx context.Context, _ S) int {
+	return PriorityFromContext(ctx)
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+func ContextTenant[S any](ctx context.Context, _ S) string {
+	return TenantFromContext(ctx)
+}
+
+type BulkheadQueueDiscipline int
+
+const (
+	BulkheadFIFO BulkheadQueueDiscipline = 0
+	// Favors fresh calls during overload
+	BulkheadLIFO BulkheadQueueDiscipline = 1
+	// Earliest deadline first, based on the deadline of a caller's context.
+	// Calls whose deadline has already passed are never admitted.
+	BulkheadEDF BulkheadQueueDiscipline = 2
+)
+
+func (d BulkheadQueueDiscipline) internal() internal.QueueDiscipline {
+	switch d {
+	case BulkheadFIFO:
+		return internal.QueueFIFO
+	case BulkheadLIFO:
+		return internal.QueueLIFO
+	case BulkheadEDF:
+		return internal.QueueEDF
+	}
+	panic("not supported")
+}
+
+type BulkheadOptions[S any] struct {
+	Concurrency int
+	Queue       int
+	// Queued calls are admitted in priority order. When the queue is full a call
+	// evicts the queued call that would be admitted last, if its priority is
+	// lower than the priority of the call. All calls have the same priority when nil.
+	Priority func(context.Context, S) int
+	// Limits the time a call may spend in the queue. Zero means no limit.
+	MaxQueueWait time.Duration
+	// Receives the time an admitted call has spent in the queue
+	OnAdmitted func(wait time.Duration)
+	// Order of queued calls with equal priority
+	Discipline BulkheadQueueDiscipline
+	// Puts each call into a per-tenant queue. Free slots are handed out across
+	// tenants with waiting calls by weight (round-robin when weights are equal).
+	// Priority and Discipline apply within a tenant's queue.
+	Tenant KeyFunc[S, string]
+	// Weight of a tenant, defaults to 1
+	TenantWeight func(tenant string) int
+	// Limits the number of queued calls per tenant. Zero means no limit.
+	TenantQueue int
+	// Enables Controlled Delay (CoDel) queue management when positive. If the
+	// minimum time calls spend in the queue stays above the target for a whole
+	// interval, the queue sheds calls waiting longer than twice the target and
+	// admits the most recent calls first until it drains. Priority and Discipline
+	// are ignored in this mode and it cannot be combined with Tenant.
+	CoDelTarget time.Duration
+	// Defaults to 100ms
+	CoDelInterval time.Duration
+}
+
+type bulkheadLimiter interface {
+	Acquire(context.Context, int, string) (time.Duration, error)
+	Release()
+	SetLimits(int, int)
+	Queued() int
+	Close()
+}
+
+type Bulkhead[S any, T any] struct {
+	bulkhead   bulkheadLimiter
+	priority   func(context.Context, S) int
+	tenant     KeyFunc[S, string]
+	onAdmitted func(time.Duration)
+	counters   policyCounters
+	gate       policyGate
+}
 
 func (pf PolicyFunc[S, T]) Bulkhead(concurrency int, queue int) PolicyFunc[S, T] {
 	policy := NewBulkheadPolicy[S, T](concurrency, queue)
@@ -15,26 +129,112 @@ func (pf PolicyFunc[S, T]) Bulkhead(concurrency int, queue int) PolicyFunc[S, T]
 }
 
 func NewBulkheadPolicy[S any, T any](concurrency int, queue int) Policy[S, T] {
-	if concurrency < 0 {
-		panic("concurrency must be >= 0")
+	return NewBulkhead[S, T](concurrency, queue).Policy()
+}
+
+func NewPriorityBulkheadPolicy[S any, T any](
+	concurrency int,
+	queue int,
+	priority func(context.Context, S) int) Policy[S, T] {
+
+	return NewBulkheadWithOptions[S, T](BulkheadOptions[S]{
+		Concurrency: concurrency,
+		Queue:       queue,
+		Priority:    priority,
+	}).Policy()
+}
+
+func NewBulkhead[S any, T any](concurrency int, queue int) *Bulkhead[S, T] {
+	return NewBulkheadWithOptions[S, T](BulkheadOptions[S]{Concurrency: concurrency, Queue: queue})
+}
+
+func NewBulkheadWithOptions[S any, T any](options BulkheadOptions[S]) *Bulkhead[S, T] {
+	queue := internal.NewWaitQueue(options.Discipline.internal())
+	if options.Tenant != nil {
+		queue = internal.NewFairWaitQueue(
+			options.Discipline.internal(),
+			options.TenantQueue,
+			options.TenantWeight)
 	}
-	if queue < 0 {
-		panic("queue must be >= 0")
+	if options.CoDelTarget > 0 {
+		if options.Tenant != nil {
+			panic("CoDel cannot be combined with tenants")
+		}
+		interval := options.CoDelInterval
+		if interval == 0 {
+			interval = 100 * time.Millisecond
+		}
+		queue = internal.NewCoDelWaitQueue(options.CoDelTarget, interval, internal.DefaultTimeProvider)
 	}
+	return &Bulkhead[S, T]{
+		bulkhead: internal.NewBulkhead(
+			options.Concurrency,
+			options.Queue,
+			options.MaxQueueWait,
+			queue),
+		priority:   options.Priority,
+		tenant:     options.Tenant,
+		onAdmitted: options.OnAdmitted,
+	}
+}
 
-	concurrencyLimiter := internal.NewBoundedSemaphore(concurrency)
-	queueLimiter := internal.NewBoundedSemaphore(concurrency + queue)
-
-	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+func (b *Bulkhead[S, T]) Policy() Policy[S, T] {
+	return gated(&b.gate, &b.counters, func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
 		var zero T
-		if !queueLimiter.TryWait() {
+		var priority int
+		if b.priority != nil {
+			priority = b.priority(ctx, s)
+		}
+		var tenant string
+		if b.tenant != nil {
+			tenant = b.tenant(ctx, s)
+		}
+		wait, err := b.bulkhead.Acquire(ctx, priority, tenant)
+		if errors.Is(err, internal.ErrBulkheadFull) || errors.Is(err, internal.ErrBulkheadEvicted) {
+			b.counters.reject()
 			return zero, ErrBulkheadRejected
 		}
-		defer queueLimiter.Release()
-		if err := concurrencyLimiter.Wait(ctx); err != nil {
+		if errors.Is(err, internal.ErrBulkheadQueueTimeout) {
+			b.counters.reject()
+			return zero, ErrBulkheadQueueTimeout
+		}
+		if errors.Is(err, internal.ErrBulkheadShed) {
+			b.counters.reject()
+			return zero, ErrBulkheadShed
+		}
+		if errors.Is(err, internal.ErrBulkheadClosed) {
+			b.counters.reject()
+			return zero, ErrPolicyClosed
+		}
+		if err != nil {
 			return zero, err
 		}
-		defer concurrencyLimiter.Release()
-		return f(ctx, s)
-	}
+		defer b.bulkhead.Release()
+		if b.onAdmitted != nil {
+			b.onAdmitted(wait)
+		}
+		return countedCall(&b.counters, ctx, f, s)
+	})
+}
+
+// SetLimits takes effect immediately and keeps in-flight and queued calls
+func (b *Bulkhead[S, T]) SetLimits(concurrency int, queue int) {
+	b.bulkhead.SetLimits(concurrency, queue)
+}
+
+// Drain lets queued and in-flight calls complete
+func (b *Bulkhead[S, T]) Drain(ctx context.Context) error {
+	return b.gate.drain(ctx)
+}
+
+// Close rejects queued calls and lets in-flight calls complete
+func (b *Bulkhead[S, T]) Close() {
+	b.gate.close()
+	b.bulkhead.Close()
+}
+
+func (b *Bulkhead[S, T]) Stats() Stats {
+	stats := b.counters.snapshot()
+	stats.Queued = int64(b.bulkhead.Queued())
+	return stats
 }
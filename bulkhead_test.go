@@ -2,6 +2,7 @@ package resilience
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -53,3 +54,287 @@ func TestBulkhead(t *testing.T) {
 		}
 	})
 }
+
+func TestPriorityBulkhead(t *testing.T) {
+	t.Run("should reject queued sheddable call in favor of critical one", func(t *testing.T) {
+		// Arrange
+		policy := NewPriorityBulkheadPolicy[string, int](1, 1, ContextPriority[string])
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		f := func(ctx context.Context, s string) (int, error) {
+			close(started)
+			<-barrier
+			return len(s), nil
+		}
+		g := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+		go policy(context.Background(), f, "running")
+		<-started
+		sheddable := make(chan error)
+		go func() {
+			_, err := policy(WithPriority(context.Background(), CriticalitySheddable), g, "sheddable")
+			sheddable <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		// Act
+		critical := make(chan int)
+		go func() {
+			result, _ := policy(WithPriority(context.Background(), CriticalityCritical), g, "critical")
+			critical <- result
+		}()
+		errSheddable := <-sheddable
+		close(barrier)
+		result := <-critical
+
+		// Assert
+		if !errors.Is(errSheddable, ErrBulkheadRejected) || result != len("critical") {
+			t.Fail()
+		}
+	})
+
+	t.Run("should use default criticality when context has no priority", func(t *testing.T) {
+		if PriorityFromContext(context.Background()) != CriticalityDefault {
+			t.Fail()
+		}
+	})
+}
+
+func TestBulkheadQueueWait(t *testing.T) {
+	t.Run("should reject call that waited in queue longer than max queue wait", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkheadWithOptions[string, int](BulkheadOptions[string]{
+			Concurrency:  1,
+			Queue:        1,
+			MaxQueueWait: 50 * time.Millisecond,
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+
+		// Act
+		_, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "bar")
+		close(barrier)
+		<-done
+
+		// Assert
+		if !errors.Is(err, ErrBulkheadQueueTimeout) || !errors.Is(err, ErrBulkheadRejected) {
+			t.Fail()
+		}
+		if bulkhead.Stats().Rejected != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should report time spent in queue by admitted call", func(t *testing.T) {
+		// Arrange
+		waits := make(chan time.Duration, 2)
+		policy := NewBulkheadWithOptions[string, int](BulkheadOptions[string]{
+			Concurrency: 1,
+			Queue:       1,
+			OnAdmitted:  func(wait time.Duration) { waits <- wait },
+		}).Policy()
+		f := func(ctx context.Context, s string) (int, error) {
+			time.Sleep(50 * time.Millisecond)
+			return len(s), nil
+		}
+		wg := sync.WaitGroup{}
+
+		// Act
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				policy(context.Background(), f, "foo")
+			}()
+		}
+		wg.Wait()
+		close(waits)
+
+		// Assert
+		var longest time.Duration
+		for wait := range waits {
+			if wait > longest {
+				longest = wait
+			}
+		}
+		if longest < 40*time.Millisecond {
+			t.Fail()
+		}
+	})
+}
+
+func TestBulkheadSetLimits(t *testing.T) {
+	t.Run("should admit queued call after concurrency is raised", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead[string, int](1, 1)
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+		queued := make(chan int)
+		go func() {
+			result, _ := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				return len(s), nil
+			}, "queued")
+			queued <- result
+		}()
+		for bulkhead.Stats().Queued != 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Act
+		bulkhead.SetLimits(2, 1)
+		result := <-queued
+		close(barrier)
+		<-done
+
+		// Assert
+		if result != len("queued") {
+			t.Fail()
+		}
+	})
+}
+
+func TestBulkheadQueueDiscipline(t *testing.T) {
+	t.Run("should admit most recent queued call first when LIFO discipline used", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkheadWithOptions[int, int](BulkheadOptions[int]{
+			Concurrency: 1,
+			Queue:       2,
+			Discipline:  BulkheadLIFO,
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		go policy(context.Background(), func(ctx context.Context, n int) (int, error) {
+			close(started)
+			<-barrier
+			return n, nil
+		}, 0)
+		<-started
+		order := make(chan int, 2)
+		f := func(ctx context.Context, n int) (int, error) {
+			order <- n
+			return n, nil
+		}
+		for i := 1; i <= 2; i++ {
+			go policy(context.Background(), f, i)
+			for bulkhead.Stats().Queued != int64(i) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+
+		// Act
+		close(barrier)
+
+		// Assert
+		if <-order != 2 || <-order != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func TestFairBulkhead(t *testing.T) {
+	t.Run("should reject call when tenant queue is full while other tenants still can queue", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkheadWithOptions[string, int](BulkheadOptions[string]{
+			Concurrency: 0,
+			Queue:       10,
+			Tenant:      func(_ context.Context, s string) string { return s },
+			TenantQueue: 1,
+		})
+		policy := bulkhead.Policy()
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		go policy(ctx, f, "noisy")
+		for bulkhead.Stats().Queued != 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Act
+		_, err := policy(ctx, f, "noisy")
+		go policy(ctx, f, "quiet")
+		for bulkhead.Stats().Queued != 2 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+
+		// Assert
+		if !errors.Is(err, ErrBulkheadRejected) {
+			t.Fail()
+		}
+	})
+}
+
+func TestCoDelBulkhead(t *testing.T) {
+	t.Run("should shed stale queued call during sustained overload", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkheadWithOptions[string, int](BulkheadOptions[string]{
+			Concurrency:   1,
+			Queue:         10,
+			CoDelTarget:   10 * time.Millisecond,
+			CoDelInterval: 50 * time.Millisecond,
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		go policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			close(started)
+			<-barrier
+			return len(s), nil
+		}, "running")
+		<-started
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+		stale := make(chan error)
+		go func() {
+			_, err := policy(context.Background(), f, "stale")
+			stale <- err
+		}()
+		for bulkhead.Stats().Queued != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(60 * time.Millisecond)
+
+		// Act
+		fresh := make(chan error)
+		go func() {
+			_, err := policy(context.Background(), f, "fresh")
+			fresh <- err
+		}()
+		errStale := <-stale
+		close(barrier)
+		errFresh := <-fresh
+
+		// Assert
+		if !errors.Is(errStale, ErrBulkheadShed) || !errors.Is(errStale, ErrBulkheadRejected) || errFresh != nil {
+			t.Fail()
+		}
+	})
+}
@@ -13,12 +13,75 @@ var ErrCircuitBroken error = errors.New("circuit broken")
 type CircuitCommit[T any] func(T, error)
 type CircuitBreaker[T any] func() (CircuitCommit[T], bool)
 
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = 0
+	CircuitOpen     CircuitState = 1
+	CircuitHalfOpen CircuitState = 2
+)
+
+type Circuit[S any, T any] struct {
+	circuitBreaker CircuitBreaker[T]
+	state          func() CircuitState
+	counters       policyCounters
+	gate           policyGate
+}
+
+func NewConsecutiveFailuresCircuit[S any, T any](
+	threshold int,
+	breakDuration time.Duration,
+	p func(T, error) bool,
+) *Circuit[S, T] {
+
+	circuitBreaker, state := consecutiveFailuresCircuitBreaker(threshold, breakDuration, p)
+	return &Circuit[S, T]{circuitBreaker: circuitBreaker, state: state}
+}
+
+func (c *Circuit[S, T]) Policy() Policy[S, T] {
+	return gated(&c.gate, &c.counters, func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		var zero T
+		commit, ok := c.circuitBreaker()
+		if !ok {
+			c.counters.reject()
+			return zero, ErrCircuitBroken
+		}
+		result, err := countedCall(&c.counters, ctx, f, s)
+		commit(result, err)
+		return result, err
+	})
+}
+
+func (c *Circuit[S, T]) Drain(ctx context.Context) error {
+	return c.gate.drain(ctx)
+}
+
+func (c *Circuit[S, T]) Close() {
+	c.gate.close()
+}
+
+func (c *Circuit[S, T]) Stats() Stats {
+	stats := c.counters.snapshot()
+	stats.CircuitState = c.state()
+	return stats
+}
+
 func ConsecutiveFailuresCircuitBreaker[T any](
 	threshold int,
 	breakDuration time.Duration,
 	p func(T, error) bool,
 ) CircuitBreaker[T] {
 
+	circuitBreaker, _ := consecutiveFailuresCircuitBreaker(threshold, breakDuration, p)
+	return circuitBreaker
+}
+
+func consecutiveFailuresCircuitBreaker[T any](
+	threshold int,
+	breakDuration time.Duration,
+	p func(T, error) bool,
+) (CircuitBreaker[T], func() CircuitState) {
+
 	circuitBreaker := internal.NewCircuitBreaker[T](
 		threshold,
 		breakDuration,
@@ -32,12 +95,16 @@ func ConsecutiveFailuresCircuitBreaker[T any](
 		circuitBreaker.Failure(result, err)
 	}
 
-	return func() (CircuitCommit[T], bool) {
+	acquire := func() (CircuitCommit[T], bool) {
 		if circuitBreaker.TryAcquire() {
 			return commit, true
 		}
 		return nil, false
 	}
+	state := func() CircuitState {
+		return CircuitState(circuitBreaker.State())
+	}
+	return acquire, state
 }
 
 func (pf PolicyFunc[S, T]) CircuitBreaker(cb CircuitBreaker[T]) PolicyFunc[S, T] {
@@ -0,0 +1,69 @@
+package resilience
+
+import (
+	"context"
+	"sync"
+)
+
+type coalescedCall[T any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	result  T
+	err     error
+}
+
+// NewCoalescingPolicy lets only one call per key be in flight and hands its
+// outcome to every concurrent caller with the same key. A caller may stop
+// waiting through its own context. The shared call is cancelled once every
+// caller interested in it has left.
+func NewCoalescingPolicy[S any, T any, K comparable](key KeyFunc[S, K]) Policy[S, T] {
+	var (
+		m     sync.Mutex
+		calls = make(map[K]*coalescedCall[T])
+	)
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		var zero T
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		k := key(ctx, s)
+		m.Lock()
+		call, ok := calls[k]
+		if !ok {
+			// The call outlives the caller that has started it, as long as others wait for it
+			callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+			call = &coalescedCall[T]{done: make(chan struct{}), cancel: cancel}
+			calls[k] = call
+			go func() {
+				defer cancel()
+				result, err := f(callCtx, s)
+				m.Lock()
+				if calls[k] == call {
+					delete(calls, k)
+				}
+				m.Unlock()
+				call.result, call.err = result, err
+				close(call.done)
+			}()
+		}
+		call.waiters++
+		m.Unlock()
+
+		select {
+		case <-call.done:
+			return call.result, call.err
+		case <-ctx.Done():
+			m.Lock()
+			defer m.Unlock()
+			call.waiters--
+			if call.waiters == 0 {
+				call.cancel()
+				if calls[k] == call {
+					delete(calls, k)
+				}
+			}
+			return zero, ctx.Err()
+		}
+	}
+}
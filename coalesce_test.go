@@ -0,0 +1,136 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalescing(t *testing.T) {
+	identity := func(ctx context.Context, s string) string { return s }
+
+	t.Run("should run one call per key and share its outcome", func(t *testing.T) {
+		// Arrange
+		policy := NewCoalescingPolicy[string, int](identity)
+		var calls atomic.Int32
+		release := make(chan struct{})
+		f := func(ctx context.Context, s string) (int, error) {
+			calls.Add(1)
+			<-release
+			return len(s), nil
+		}
+		var wg sync.WaitGroup
+		results := make(chan int, 10)
+
+		// Act
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(s string) {
+				defer wg.Done()
+				v, _ := policy(context.Background(), f, s)
+				results <- v
+			}([]string{"foo", "ba"}[i%2])
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		close(results)
+
+		// Assert
+		if calls.Load() != 2 {
+			t.Fail()
+		}
+		sum := 0
+		for v := range results {
+			sum += v
+		}
+		if sum != 5*3+5*2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should run call again once previous one has completed", func(t *testing.T) {
+		// Arrange
+		policy := NewCoalescingPolicy[string, int](identity)
+		var calls int
+		f := func(ctx context.Context, s string) (int, error) {
+			calls++
+			return calls, nil
+		}
+
+		// Act
+		v1, _ := policy(context.Background(), f, "foo")
+		v2, _ := policy(context.Background(), f, "foo")
+
+		// Assert
+		if v1 != 1 || v2 != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should keep shared call running while someone waits for it", func(t *testing.T) {
+		// Arrange
+		policy := NewCoalescingPolicy[string, int](identity)
+		release := make(chan struct{})
+		f := func(ctx context.Context, s string) (int, error) {
+			select {
+			case <-release:
+				return len(s), nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		abandoned := make(chan error)
+		go func() {
+			_, err := policy(ctx, f, "foo")
+			abandoned <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		result := make(chan int)
+		go func() {
+			v, _ := policy(context.Background(), f, "foo")
+			result <- v
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		// Act
+		cancel()
+		err := <-abandoned
+		close(release)
+
+		// Assert
+		if !errors.Is(err, context.Canceled) || <-result != 3 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should cancel shared call when every caller has left", func(t *testing.T) {
+		// Arrange
+		policy := NewCoalescingPolicy[string, int](identity)
+		cancelled := make(chan struct{})
+		f := func(ctx context.Context, s string) (int, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return 0, ctx.Err()
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// Act
+		_, err := policy(ctx, f, "foo")
+
+		// Assert
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fail()
+		}
+		select {
+		case <-cancelled:
+		case <-time.After(1 * time.Second):
+			t.Fail()
+		}
+	})
+}
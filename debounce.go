@@ -4,34 +4,223 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var ErrDebounced = errors.New("call debounced")
 
+type replayKey struct{}
+
+type replayMarker struct {
+	replayed atomic.Bool
+}
+
+// WithReplayMarker prepares the context to record whether the outcome of a call
+// made with it was replayed by a debounce policy instead of being computed
+func WithReplayMarker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replayKey{}, &replayMarker{})
+}
+
+// Replayed reports whether the most recent call made with the context received
+// a replayed outcome. The context must be prepared with WithReplayMarker.
+func Replayed(ctx context.Context) bool {
+	marker, ok := ctx.Value(replayKey{}).(*replayMarker)
+	return ok && marker.replayed.Load()
+}
+
+func markReplayed(ctx context.Context, replayed bool) {
+	if marker, ok := ctx.Value(replayKey{}).(*replayMarker); ok {
+		marker.replayed.Store(replayed)
+	}
+}
+
 func (pf PolicyFunc[S, T]) DebounceFirst(d time.Duration) PolicyFunc[S, T] {
 	return NewDebounceFirstPolicy[S, T](d).Bind(pf)
 }
 
+type Debouncer[S any, T any] struct {
+	policy Policy[S, T]
+	// Reports whether there is no debounce window open, i.e. forgetting the
+	// debouncer would not change the outcome of the next call
+	idle     func() bool
+	counters policyCounters
+	gate     policyGate
+}
+
 func NewDebounceFirstPolicy[S any, T any](d time.Duration) Policy[S, T] {
+	return NewDebouncer[S, T](d).Policy()
+}
+
+func NewDebouncer[S any, T any](d time.Duration) *Debouncer[S, T] {
+	return newLeadingDebouncer[S, T](d, false)
+}
+
+func (pf PolicyFunc[S, T]) DebounceReplay(d time.Duration) PolicyFunc[S, T] {
+	return NewDebounceReplayPolicy[S, T](d).Bind(pf)
+}
+
+func NewDebounceReplayPolicy[S any, T any](d time.Duration) Policy[S, T] {
+	return NewReplayDebouncer[S, T](d).Policy()
+}
+
+// NewReplayDebouncer runs the first call of a burst like NewDebouncer, but the
+// rest of the burst receives the outcome of the most recent executed call
+// instead of ErrDebounced. A context prepared with WithReplayMarker tells
+// whether the outcome was replayed. ErrDebounced is returned while nothing has
+// completed yet.
+func NewReplayDebouncer[S any, T any](d time.Duration) *Debouncer[S, T] {
+	return newLeadingDebouncer[S, T](d, true)
+}
+
+func newLeadingDebouncer[S any, T any](d time.Duration, replay bool) *Debouncer[S, T] {
 	var (
 		zero         T
 		nextCallTime time.Time
+		last         *result[T]
 		m            = sync.Mutex{}
 	)
 
-	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+	debouncer := &Debouncer[S, T]{}
+	debouncer.idle = func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return !time.Now().Before(nextCallTime)
+	}
+	debouncer.policy = func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
 		m.Lock()
 		now := time.Now()
 
 		if now.Before(nextCallTime) {
 			nextCallTime = now.Add(d)
+			if replay && last != nil {
+				outcome := *last
+				m.Unlock()
+				markReplayed(ctx, true)
+				return outcome.Value, outcome.Err
+			}
 			m.Unlock()
+			debouncer.counters.reject()
 			return zero, ErrDebounced
 		}
 		nextCallTime = now.Add(d)
 		m.Unlock()
 
-		return f(ctx, s)
+		markReplayed(ctx, false)
+		value, err := countedCall(&debouncer.counters, ctx, f, s)
+		if replay {
+			m.Lock()
+			last = &result[T]{value, err}
+			m.Unlock()
+		}
+		return value, err
+	}
+	return debouncer
+}
+
+func (pf PolicyFunc[S, T]) DebounceLast(d time.Duration, maxWait time.Duration) PolicyFunc[S, T] {
+	return NewDebounceLastPolicy[S, T](d, maxWait).Bind(pf)
+}
+
+func NewDebounceLastPolicy[S any, T any](d time.Duration, maxWait time.Duration) Policy[S, T] {
+	return NewTrailingDebouncer[S, T](d, maxWait).Policy()
+}
+
+// A burst of calls separated by less than the debounce duration
+type debounceBurst[S any, T any] struct {
+	ctx    context.Context
+	f      func(context.Context, S) (T, error)
+	s      S
+	due    time.Time
+	latest time.Time // the burst runs no later than that, zero means no limit
+	timer  *time.Timer
+	done   chan struct{}
+	result T
+	err    error
+}
+
+// NewTrailingDebouncer runs `f` once calls stop arriving for `d`, with the input
+// of the latest call, and hands its outcome to every caller of the burst.
+// A burst that keeps going runs at least once per `maxWait` unless it is zero.
+// The execution is detached from the cancellation of callers' contexts, a caller
+// that gives up waiting receives its context error.
+func NewTrailingDebouncer[S any, T any](d time.Duration, maxWait time.Duration) *Debouncer[S, T] {
+	if maxWait < 0 {
+		panic("max wait must be >= 0")
+	}
+	var (
+		zero    T
+		current *debounceBurst[S, T]
+		m       = sync.Mutex{}
+	)
+
+	debouncer := &Debouncer[S, T]{}
+	debouncer.idle = func() bool {
+		m.Lock()
+		defer m.Unlock()
+		return current == nil
+	}
+	fire := func(burst *debounceBurst[S, T]) {
+		m.Lock()
+		now := time.Now()
+		if now.Before(burst.due) {
+			burst.timer.Reset(burst.due.Sub(now))
+			m.Unlock()
+			return
+		}
+		current = nil
+		m.Unlock()
+
+		burst.result, burst.err = countedCall(&debouncer.counters, context.WithoutCancel(burst.ctx), burst.f, burst.s)
+		close(burst.done)
 	}
+
+	debouncer.policy = func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		m.Lock()
+		now := time.Now()
+		burst := current
+		if burst == nil {
+			burst = &debounceBurst[S, T]{done: make(chan struct{})}
+			if maxWait > 0 {
+				burst.latest = now.Add(maxWait)
+			}
+			current = burst
+		}
+		burst.ctx, burst.f, burst.s = ctx, f, s
+		burst.due = now.Add(d)
+		if !burst.latest.IsZero() && burst.latest.Before(burst.due) {
+			burst.due = burst.latest
+		}
+		if burst.timer == nil {
+			burst.timer = time.AfterFunc(burst.due.Sub(now), func() { fire(burst) })
+		}
+		m.Unlock()
+
+		select {
+		case <-burst.done:
+			return burst.result, burst.err
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+	return debouncer
+}
+
+func (d *Debouncer[S, T]) Policy() Policy[S, T] {
+	return gated(&d.gate, &d.counters, d.policy)
+}
+
+func (d *Debouncer[S, T]) Drain(ctx context.Context) error {
+	return d.gate.drain(ctx)
+}
+
+func (d *Debouncer[S, T]) Close() {
+	d.gate.close()
+}
+
+func (d *Debouncer[S, T]) Stats() Stats {
+	return d.counters.snapshot()
 }
@@ -3,6 +3,8 @@ package resilience
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -151,3 +153,185 @@ func newSliceIndexer[T any](items []T) func(context.Context, int) (T, error) {
 		return items[i], nil
 	}
 }
+
+func TestDebounceLast(t *testing.T) {
+	t.Run("should run once with the latest input and share the outcome", func(t *testing.T) {
+		// Arrange
+		policy := NewDebounceLastPolicy[int, int](50*time.Millisecond, 0)
+		var calls atomic.Int32
+		var inputs []int
+		f := func(ctx context.Context, n int) (int, error) {
+			calls.Add(1)
+			inputs = append(inputs, n)
+			return n * 10, nil
+		}
+		results := make(chan int, 3)
+
+		// Act
+		for i := 1; i <= 3; i++ {
+			go func(n int) {
+				v, _ := policy(context.Background(), f, n)
+				results <- v
+			}(i)
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// Assert
+		for i := 0; i < 3; i++ {
+			if v := <-results; v != 30 {
+				t.Fail()
+			}
+		}
+		if calls.Load() != 1 || inputs[0] != 3 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should share error with every caller of the burst", func(t *testing.T) {
+		// Arrange
+		policy := NewDebounceLastPolicy[int, int](20*time.Millisecond, 0)
+		f := func(ctx context.Context, n int) (int, error) {
+			return 0, errSomethingWentWrong
+		}
+		errs := make(chan error, 2)
+
+		// Act
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := policy(context.Background(), f, 0)
+				errs <- err
+			}()
+		}
+
+		// Assert
+		if !errors.Is(<-errs, errSomethingWentWrong) || !errors.Is(<-errs, errSomethingWentWrong) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should run periodically when calls keep coming", func(t *testing.T) {
+		// Arrange
+		policy := NewDebounceLastPolicy[int, int](50*time.Millisecond, 60*time.Millisecond)
+		var calls atomic.Int32
+		f := func(ctx context.Context, n int) (int, error) {
+			calls.Add(1)
+			return n, nil
+		}
+		var wg sync.WaitGroup
+
+		// Act
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(n int) {
+				defer wg.Done()
+				policy(context.Background(), f, n)
+			}(i)
+			time.Sleep(10 * time.Millisecond)
+		}
+		wg.Wait()
+
+		// Assert
+		if calls.Load() < 3 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should let caller stop waiting without cancelling the execution", func(t *testing.T) {
+		// Arrange
+		policy := NewDebounceLastPolicy[int, int](50*time.Millisecond, 0)
+		var cancelled atomic.Bool
+		f := func(ctx context.Context, n int) (int, error) {
+			cancelled.Store(ctx.Err() != nil)
+			return n, nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan int)
+		go func() {
+			v, _ := policy(context.Background(), f, 1)
+			result <- v
+		}()
+		time.Sleep(5 * time.Millisecond)
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		// Act
+		_, err := policy(ctx, f, 2)
+
+		// Assert
+		if !errors.Is(err, context.Canceled) || <-result != 2 || cancelled.Load() {
+			t.Fail()
+		}
+	})
+}
+
+func TestDebounceReplay(t *testing.T) {
+	t.Run("should replay outcome of executed call within debounce window", func(t *testing.T) {
+		// Arrange
+		policy := NewDebounceReplayPolicy[int, int](1 * time.Hour)
+		f := newSliceIndexer([]int{10, 20, 30})
+		ctx := WithReplayMarker(context.Background())
+
+		// Act
+		v1, err1 := policy(ctx, f, 0)
+		replayed1 := Replayed(ctx)
+		v2, err2 := policy(ctx, f, 1)
+		replayed2 := Replayed(ctx)
+
+		// Assert
+		if v1 != 10 || err1 != nil || replayed1 {
+			t.Fail()
+		}
+		if v2 != 10 || err2 != nil || !replayed2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should replay error of executed call", func(t *testing.T) {
+		// Arrange
+		policy := NewDebounceReplayPolicy[int, int](1 * time.Hour)
+		f := func(ctx context.Context, n int) (int, error) {
+			return 0, errSomethingWentWrong
+		}
+
+		// Act
+		policy(context.Background(), f, 0)
+		_, err := policy(context.Background(), f, 0)
+
+		// Assert
+		if !errors.Is(err, errSomethingWentWrong) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should return debounced error while there is nothing to replay", func(t *testing.T) {
+		// Arrange
+		policy := NewDebounceReplayPolicy[string, int](1 * time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(ctx, func(ctx context.Context, s string) (int, error) {
+				close(started)
+				return spin[string, int](ctx, s)
+			}, "foo")
+		}()
+		<-started
+
+		// Act
+		replayCtx := WithReplayMarker(context.Background())
+		_, err := policy(replayCtx, spin, "foo")
+		cancel()
+		<-done
+
+		// Assert
+		if !errors.Is(err, ErrDebounced) || Replayed(replayCtx) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not report replay without marker", func(t *testing.T) {
+		if Replayed(context.Background()) {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrPolicyClosed = errors.New("policy closed")
+
+type Drainer interface {
+	// Drain stops admitting new calls and blocks until in-flight calls complete or ctx is done
+	Drain(ctx context.Context) error
+	// Close stops admitting new calls without waiting for in-flight ones
+	Close()
+}
+
+func DrainAll(ctx context.Context, drainers ...Drainer) error {
+	errs := make([]error, len(drainers))
+	wg := sync.WaitGroup{}
+	for i, drainer := range drainers {
+		wg.Add(1)
+		go func(i int, drainer Drainer) {
+			defer wg.Done()
+			errs[i] = drainer.Drain(ctx)
+		}(i, drainer)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Gate keeps track of calls that passed through a policy and stops letting
+// new ones in once it has been closed
+type policyGate struct {
+	m       sync.Mutex
+	closed  bool
+	active  int
+	drained chan struct{}
+}
+
+func (g *policyGate) enter() bool {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed {
+		return false
+	}
+	g.active++
+	return true
+}
+
+func (g *policyGate) leave() {
+	g.m.Lock()
+	defer g.m.Unlock()
+	g.active--
+	if g.closed && g.active == 0 {
+		close(g.drained)
+	}
+}
+
+func (g *policyGate) close() {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed {
+		return
+	}
+	g.closed = true
+	g.drained = make(chan struct{})
+	if g.active == 0 {
+		close(g.drained)
+	}
+}
+
+func (g *policyGate) drain(ctx context.Context) error {
+	g.close()
+	select {
+	case <-g.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func gated[S any, T any](g *policyGate, c *policyCounters, policy Policy[S, T]) Policy[S, T] {
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		var zero T
+		if !g.enter() {
+			c.reject()
+			return zero, ErrPolicyClosed
+		}
+		defer g.leave()
+		return policy(ctx, f, s)
+	}
+}
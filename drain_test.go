@@ -0,0 +1,137 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrain(t *testing.T) {
+	t.Run("should reject new calls and wait for in-flight calls", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead[string, int](1, 1)
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+		drained := make(chan error)
+		go func() {
+			drained <- bulkhead.Drain(context.Background())
+		}()
+		for !isClosed(&bulkhead.gate) {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Act
+		_, errNew := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "bar")
+		close(barrier)
+		errDrain := <-drained
+		<-done
+
+		// Assert
+		if !errors.Is(errNew, ErrPolicyClosed) || errDrain != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("should stop waiting when context is done", func(t *testing.T) {
+		// Arrange
+		debouncer := NewDebouncer[string, int](1 * time.Hour)
+		policy := debouncer.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// Act
+		err := debouncer.Drain(ctx)
+		close(barrier)
+		<-done
+
+		// Assert
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject queued calls when bulkhead closed", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead[string, int](1, 1)
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		go policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			close(started)
+			<-barrier
+			return len(s), nil
+		}, "foo")
+		<-started
+		queued := make(chan error)
+		go func() {
+			_, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				return len(s), nil
+			}, "bar")
+			queued <- err
+		}()
+		for bulkhead.Stats().Queued != 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Act
+		bulkhead.Close()
+		err := <-queued
+		close(barrier)
+
+		// Assert
+		if !errors.Is(err, ErrPolicyClosed) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should drain set of policies together", func(t *testing.T) {
+		// Arrange
+		rateLimiter := NewTokenBucketRateLimiter[string, int](1*time.Second, 10)
+		circuit := NewConsecutiveFailuresCircuit[string, int](1, 1*time.Second, RejectOnError)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		err := DrainAll(context.Background(), rateLimiter, circuit)
+		_, err1 := rateLimiter.Policy()(context.Background(), f, "foo")
+		_, err2 := circuit.Policy()(context.Background(), f, "foo")
+
+		// Assert
+		if err != nil || !errors.Is(err1, ErrPolicyClosed) || !errors.Is(err2, ErrPolicyClosed) {
+			t.Fail()
+		}
+	})
+}
+
+func isClosed(g *policyGate) bool {
+	g.m.Lock()
+	defer g.m.Unlock()
+	return g.closed
+}
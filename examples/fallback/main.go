@@ -18,6 +18,14 @@ func main() {
 
 	resilience.NewPanicFallbackPolicy[S, T](resilience.IdentityFallback[T])
 
+	// Fallbacks that only run on specific errors and see the input
+	resilience.NewFallbackChainPolicy[S, T](
+		resilience.FallbackOnErrorIs[T](resilience.ErrTimeoutRejected),
+		func(_ context.Context, _ S, _ T, _ error) (T, error) {
+			panic("not implemented")
+		},
+	)
+
 	resilience.NewPanicFallbackPolicy[S, T](func(_ context.Context, _ T, _ error) (T, error) {
 		panic("not implemented")
 	})
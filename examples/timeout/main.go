@@ -25,4 +25,9 @@ func main() {
 
 	// Pessimistic timeout policy
 	resilience.NewTimeoutPolicy[S, T](timeout, resilience.PessimisticTimeoutPolicy)
+
+	// Timeout picked per call, zero means no timeout
+	resilience.NewDynamicTimeoutPolicy[S, T](func(ctx context.Context, s S) time.Duration {
+		return timeout
+	}, resilience.OptimisticTimeoutPolicy)
 }
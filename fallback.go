@@ -23,6 +23,79 @@ func NewFallbackPolicy[S any, T any](fallback FallbackFunc[T]) Policy[S, T] {
 	}
 }
 
+// FallbackCondition decides whether the outcome of a call needs a fallback
+type FallbackCondition[T any] func(result T, err error) bool
+
+// InputFallbackFunc is a fallback that also receives the input of the call
+type InputFallbackFunc[S any, T any] func(ctx context.Context, s S, result T, err error) (T, error)
+
+func FallbackOnError[T any](_ T, err error) bool {
+	return err != nil
+}
+
+func FallbackOnErrorIs[T any](targets ...error) FallbackCondition[T] {
+	return func(_ T, err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func FallbackOnErrorAs[T any, E error]() FallbackCondition[T] {
+	return func(_ T, err error) bool {
+		var target E
+		return errors.As(err, &target)
+	}
+}
+
+func (pf PolicyFunc[S, T]) ConditionalFallback(
+	condition FallbackCondition[T],
+	f InputFallbackFunc[S, T]) PolicyFunc[S, T] {
+
+	return NewConditionalFallbackPolicy[S, T](condition, f).Bind(pf)
+}
+
+// NewConditionalFallbackPolicy runs the fallback only when the condition matches the outcome
+func NewConditionalFallbackPolicy[S any, T any](
+	condition FallbackCondition[T],
+	fallback InputFallbackFunc[S, T]) Policy[S, T] {
+
+	return NewFallbackChainPolicy[S, T](condition, fallback)
+}
+
+func (pf PolicyFunc[S, T]) FallbackChain(
+	condition FallbackCondition[T],
+	fallbacks ...InputFallbackFunc[S, T]) PolicyFunc[S, T] {
+
+	return NewFallbackChainPolicy[S, T](condition, fallbacks...).Bind(pf)
+}
+
+// NewFallbackChainPolicy tries the fallbacks in sequence when the condition
+// matches the outcome, until one of them succeeds. Each fallback receives the
+// outcome of the previous attempt. The outcome of the last fallback is returned
+// when all of them fail.
+func NewFallbackChainPolicy[S any, T any](
+	condition FallbackCondition[T],
+	fallbacks ...InputFallbackFunc[S, T]) Policy[S, T] {
+
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		result, err := f(ctx, s)
+		if !condition(result, err) {
+			return result, err
+		}
+		for _, fallback := range fallbacks {
+			result, err = fallback(ctx, s, result, err)
+			if err == nil {
+				return result, nil
+			}
+		}
+		return result, err
+	}
+}
+
 func (pf PolicyFunc[S, T]) PanicFallback(f FallbackFunc[T]) PolicyFunc[S, T] {
 	return NewPanicFallbackPolicy[S, T](f).Bind(pf)
 }
@@ -3,6 +3,7 @@ package resilience
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -82,3 +83,99 @@ func TestFallback(t *testing.T) {
 		}
 	})
 }
+
+func TestConditionalFallback(t *testing.T) {
+	failWith := func(err error) func(context.Context, string) (int, error) {
+		return func(ctx context.Context, s string) (int, error) {
+			return 0, err
+		}
+	}
+	inputLength := func(ctx context.Context, s string, result int, err error) (int, error) {
+		return len(s), nil
+	}
+
+	t.Run("should run fallback with input when error matches", func(t *testing.T) {
+		// Arrange
+		policy := NewConditionalFallbackPolicy[string, int](FallbackOnErrorIs[int](errSomethingWentWrong), inputLength)
+
+		// Act
+		result, err := policy(context.Background(), failWith(fmt.Errorf("wrapped: %w", errSomethingWentWrong)), "foo")
+
+		// Assert
+		if result != 3 || err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not run fallback when error does not match", func(t *testing.T) {
+		// Arrange
+		policy := NewConditionalFallbackPolicy[string, int](FallbackOnErrorIs[int](ErrTimeoutRejected), inputLength)
+
+		// Act
+		_, err := policy(context.Background(), failWith(errSomethingWentWrong), "foo")
+
+		// Assert
+		if err != errSomethingWentWrong {
+			t.Fail()
+		}
+	})
+
+	t.Run("should run fallback when error matches by type", func(t *testing.T) {
+		// Arrange
+		policy := NewConditionalFallbackPolicy[string, int](FallbackOnErrorAs[int, *TimeoutError](), inputLength)
+
+		// Act
+		result1, err1 := policy(context.Background(), failWith(&TimeoutError{}), "foo")
+		_, err2 := policy(context.Background(), failWith(ErrTimeoutRejected), "foo")
+
+		// Assert
+		if result1 != 3 || err1 != nil || err2 != ErrTimeoutRejected {
+			t.Fail()
+		}
+	})
+
+	t.Run("should try fallbacks in sequence until one succeeds", func(t *testing.T) {
+		// Arrange
+		var tried []int
+		failing := func(ctx context.Context, s string, result int, err error) (int, error) {
+			tried = append(tried, 1)
+			return 0, fmt.Errorf("cache miss: %w", err)
+		}
+		succeeding := func(ctx context.Context, s string, result int, err error) (int, error) {
+			tried = append(tried, 2)
+			if !errors.Is(err, errSomethingWentWrong) {
+				t.Fail()
+			}
+			return len(s), nil
+		}
+		unreachable := func(ctx context.Context, s string, result int, err error) (int, error) {
+			tried = append(tried, 3)
+			return 0, nil
+		}
+		policy := NewFallbackChainPolicy[string, int](FallbackOnError[int], failing, succeeding, unreachable)
+
+		// Act
+		result, err := policy(context.Background(), failWith(errSomethingWentWrong), "foo")
+
+		// Assert
+		if result != 3 || err != nil || len(tried) != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should return outcome of the last fallback when all of them fail", func(t *testing.T) {
+		// Arrange
+		failing := func(ctx context.Context, s string, result int, err error) (int, error) {
+			return -1, ErrTimeoutRejected
+		}
+		policy := NewFallbackChainPolicy[string, int](FallbackOnError[int], failing, failing)
+
+		// Act
+		result, err := policy(context.Background(), failWith(errSomethingWentWrong), "foo")
+
+		// Assert
+		if result != -1 || err != ErrTimeoutRejected {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrBulkheadFull = errors.New("bulkhead is full")
+var ErrBulkheadEvicted = errors.New("evicted from bulkhead queue")
+var ErrBulkheadQueueTimeout = errors.New("bulkhead queue wait timed out")
+var ErrBulkheadClosed = errors.New("bulkhead closed")
+
+type bulkhead struct {
+	sync.Mutex
+	concurrency  int
+	queueSize    int
+	maxQueueWait time.Duration
+	inFlight     int
+	queue        waitQueue
+	seq          uint64
+	closed       bool
+}
+
+// A zero maxQueueWait means that a caller waits in the queue until its context is done
+func NewBulkhead(concurrency int, queue int, maxQueueWait time.Duration, waitQueue waitQueue) *bulkhead {
+	validateBulkheadLimits(concurrency, queue)
+	if maxQueueWait < 0 {
+		panic("max queue wait must be >= 0")
+	}
+	return &bulkhead{
+		concurrency:  concurrency,
+		queueSize:    queue,
+		maxQueueWait: maxQueueWait,
+		queue:        waitQueue,
+	}
+}
+
+// Acquire blocks until the caller is admitted, rejected or ctx is done and
+// reports how long the caller has been waiting in the queue.
+// Every successful call must be followed by Release.
+func (b *bulkhead) Acquire(ctx context.Context, priority int, tenant string) (time.Duration, error) {
+	b.Lock()
+	if b.closed {
+		b.Unlock()
+		return 0, ErrBulkheadClosed
+	}
+	if b.inFlight < b.concurrency && b.queue.Len() == 0 {
+		b.inFlight++
+		b.Unlock()
+		return 0, nil
+	}
+	b.seq++
+	deadline, _ := ctx.Deadline()
+	w := newWaiter(priority, deadline, b.seq)
+	w.tenant = tenant
+	if b.queue.Len() >= b.queueSize && !b.evict(w) {
+		b.Unlock()
+		return 0, ErrBulkheadFull
+	}
+	if !b.queue.Push(w) {
+		b.Unlock()
+		return 0, ErrBulkheadFull
+	}
+	b.Unlock()
+
+	enqueued := time.Now()
+	var timeout <-chan time.Time
+	if b.maxQueueWait > 0 {
+		timer := time.NewTimer(b.maxQueueWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var err error
+	select {
+	case <-w.ready:
+		return time.Since(enqueued), w.err
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timeout:
+		err = ErrBulkheadQueueTimeout
+	}
+
+	b.Lock()
+	if b.queue.Remove(w) {
+		b.Unlock()
+		return time.Since(enqueued), err
+	}
+	b.Unlock()
+	// The waiter has already been admitted or rejected concurrently
+	<-w.ready
+	if w.err == nil {
+		b.Release()
+	}
+	return time.Since(enqueued), err
+}
+
+func (b *bulkhead) Release() {
+	b.Lock()
+	defer b.Unlock()
+	b.inFlight--
+	b.dispatch()
+}
+
+// SetLimits neither drops in-flight nor queued callers. When the limits shrink
+// new callers are admitted only after the excess drains away.
+func (b *bulkhead) SetLimits(concurrency int, queue int) {
+	validateBulkheadLimits(concurrency, queue)
+	b.Lock()
+	defer b.Unlock()
+	b.concurrency = concurrency
+	b.queueSize = queue
+	b.dispatch()
+}
+
+// Close rejects queued callers as well as new ones. In-flight callers are not affected.
+func (b *bulkhead) Close() {
+	b.Lock()
+	defer b.Unlock()
+	b.closed = true
+	for w := b.queue.Pop(); w != nil; w = b.queue.Pop() {
+		w.reject(ErrBulkheadClosed)
+	}
+}
+
+func (b *bulkhead) InFlight() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.inFlight
+}
+
+func (b *bulkhead) Queued() int {
+	b.Lock()
+	defer b.Unlock()
+	return b.queue.Len()
+}
+
+func (b *bulkhead) dispatch() {
+	for b.inFlight < b.concurrency {
+		w := b.queue.Pop()
+		if w == nil {
+			return
+		}
+		b.inFlight++
+		w.admit()
+	}
+}
+
+func (b *bulkhead) evict(newcomer *waiter) bool {
+	q, ok := b.queue.(evictingWaitQueue)
+	if !ok {
+		return false
+	}
+	victim := q.Victim(newcomer)
+	if victim == nil {
+		return false
+	}
+	q.Remove(victim)
+	victim.reject(ErrBulkheadEvicted)
+	return true
+}
+
+func validateBulkheadLimits(concurrency int, queue int) {
+	if concurrency < 0 {
+		panic("concurrency must be >= 0")
+	}
+	if queue < 0 {
+		panic("queue must be >= 0")
+	}
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBulkhead(t *testing.T) {
+	t.Run("should admit queued callers in priority order", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(1, 3, 0, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+		admitted := make(chan int, 3)
+		for i, priority := range []int{1, 3, 2} {
+			go func(priority int) {
+				bulkhead.Acquire(context.Background(), priority, "")
+				admitted <- priority
+			}(priority)
+			waitQueued(bulkhead, i+1)
+		}
+
+		// Act
+		var order []int
+		for i := 0; i < 3; i++ {
+			bulkhead.Release()
+			order = append(order, <-admitted)
+		}
+
+		// Assert
+		if order[0] != 3 || order[1] != 2 || order[2] != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should evict queued caller with lower priority when queue is full", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(1, 1, 0, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+		evicted := make(chan error)
+		go func() {
+			_, err := bulkhead.Acquire(context.Background(), 1, "")
+			evicted <- err
+		}()
+		waitQueued(bulkhead, 1)
+
+		// Act
+		_, errSame := bulkhead.Acquire(context.Background(), 1, "")
+		go bulkhead.Acquire(context.Background(), 2, "")
+		errEvicted := <-evicted
+
+		// Assert
+		if !errors.Is(errSame, ErrBulkheadFull) || !errors.Is(errEvicted, ErrBulkheadEvicted) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should leave queue when context is done", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(1, 1, 0, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Act
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err := bulkhead.Acquire(ctx, 0, "")
+
+		// Assert
+		if !errors.Is(err, context.Canceled) || bulkhead.Queued() != 0 || bulkhead.InFlight() != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func TestBulkheadQueueWait(t *testing.T) {
+	t.Run("should leave queue when max queue wait elapsed", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(1, 1, 50*time.Millisecond, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+
+		// Act
+		waited, err := bulkhead.Acquire(context.Background(), 0, "")
+
+		// Assert
+		if !errors.Is(err, ErrBulkheadQueueTimeout) || waited < 50*time.Millisecond || bulkhead.Queued() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should report how long admitted caller waited", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(1, 1, 0, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+
+		// Act
+		time.AfterFunc(50*time.Millisecond, bulkhead.Release)
+		waited, err := bulkhead.Acquire(context.Background(), 0, "")
+
+		// Assert
+		if err != nil || waited < 50*time.Millisecond {
+			t.Fail()
+		}
+	})
+}
+
+func TestBulkheadSetLimits(t *testing.T) {
+	t.Run("should admit queued callers when concurrency grows", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(1, 2, 0, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+		admitted := make(chan struct{}, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				bulkhead.Acquire(context.Background(), 0, "")
+				admitted <- struct{}{}
+			}()
+		}
+		waitQueued(bulkhead, 2)
+
+		// Act
+		bulkhead.SetLimits(3, 2)
+		<-admitted
+		<-admitted
+
+		// Assert
+		if bulkhead.InFlight() != 3 || bulkhead.Queued() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should keep in-flight callers and admit new ones only below new limit when concurrency shrinks", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(2, 1, 0, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+		bulkhead.Acquire(context.Background(), 0, "")
+
+		// Act
+		bulkhead.SetLimits(1, 1)
+		admitted := make(chan struct{})
+		go func() {
+			bulkhead.Acquire(context.Background(), 0, "")
+			close(admitted)
+		}()
+		waitQueued(bulkhead, 1)
+		bulkhead.Release()
+		inFlightAfterFirstRelease := bulkhead.InFlight()
+		bulkhead.Release()
+		<-admitted
+
+		// Assert
+		if inFlightAfterFirstRelease != 1 || bulkhead.InFlight() != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject new callers when queue shrinks below queued callers", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(0, 2, 0, NewFifoWaitQueue())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		for i := 0; i < 2; i++ {
+			go bulkhead.Acquire(ctx, 0, "")
+		}
+		waitQueued(bulkhead, 2)
+
+		// Act
+		bulkhead.SetLimits(0, 1)
+		_, err := bulkhead.Acquire(ctx, 0, "")
+
+		// Assert
+		if !errors.Is(err, ErrBulkheadFull) || bulkhead.Queued() != 2 {
+			t.Fail()
+		}
+	})
+}
+
+func TestBulkheadClose(t *testing.T) {
+	t.Run("should reject queued and new callers when closed", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead(1, 1, 0, NewFifoWaitQueue())
+		bulkhead.Acquire(context.Background(), 0, "")
+		queued := make(chan error)
+		go func() {
+			_, err := bulkhead.Acquire(context.Background(), 0, "")
+			queued <- err
+		}()
+		waitQueued(bulkhead, 1)
+
+		// Act
+		bulkhead.Close()
+		errQueued := <-queued
+		_, errNew := bulkhead.Acquire(context.Background(), 0, "")
+
+		// Assert
+		if !errors.Is(errQueued, ErrBulkheadClosed) || !errors.Is(errNew, ErrBulkheadClosed) {
+			t.Fail()
+		}
+		if bulkhead.InFlight() != 1 {
+			t.Fail()
+		}
+	})
+}
+
+func waitQueued(b *bulkhead, n int) {
+	for b.Queued() < n {
+		time.Sleep(time.Millisecond)
+	}
+}
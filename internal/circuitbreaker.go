@@ -59,6 +59,12 @@ func (cb *circuitBreaker[T]) TryAcquire() bool {
 	return true
 }
 
+func (cb *circuitBreaker[T]) State() circuitState {
+	cb.Lock()
+	defer cb.Unlock()
+	return cb.state
+}
+
 func (cb *circuitBreaker[T]) Success() {
 	cb.Lock()
 	defer cb.Unlock()
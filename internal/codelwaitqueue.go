@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"container/list"
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrBulkheadShed = errors.New("shed from bulkhead queue")
+
+// Controlled Delay (CoDel) queue management. The queue is considered overloaded
+// when the minimum time waiters spend in the queue stays above `target` for a
+// whole `interval`. While overloaded the queue serves the most recent waiters
+// first and sheds waiters that have been waiting longer than 2 * `target`,
+// until it drains.
+type codelWaitQueue struct {
+	items        *list.List // in order of arrival
+	target       time.Duration
+	interval     time.Duration
+	minDelay     time.Duration
+	intervalEnd  time.Time
+	overloaded   bool
+	timeProvider timeProvider
+}
+
+func NewCoDelWaitQueue(target time.Duration, interval time.Duration, timeProvider timeProvider) *codelWaitQueue {
+	if target <= 0 {
+		panic("target must be > 0")
+	}
+	if interval <= 0 {
+		panic("interval must be > 0")
+	}
+	return &codelWaitQueue{
+		items:        list.New(),
+		target:       target,
+		interval:     interval,
+		minDelay:     math.MaxInt64,
+		timeProvider: timeProvider,
+	}
+}
+
+func (q *codelWaitQueue) Len() int {
+	return q.items.Len()
+}
+
+func (q *codelWaitQueue) Push(w *waiter) bool {
+	now := q.timeProvider.UtcNow()
+	q.update(now)
+	q.shed(now)
+	w.enqueued = now
+	w.elem = q.items.PushBack(w)
+	return true
+}
+
+func (q *codelWaitQueue) Pop() *waiter {
+	now := q.timeProvider.UtcNow()
+	q.update(now)
+	q.shed(now)
+
+	el := q.items.Front()
+	if q.overloaded {
+		el = q.items.Back()
+	}
+	if el == nil {
+		return nil
+	}
+	w := q.remove(el)
+	if delay := now.Sub(w.enqueued); delay < q.minDelay {
+		q.minDelay = delay
+	}
+	return w
+}
+
+func (q *codelWaitQueue) Remove(w *waiter) bool {
+	if w.elem == nil {
+		return false
+	}
+	q.remove(w.elem)
+	return true
+}
+
+func (q *codelWaitQueue) update(now time.Time) {
+	if q.intervalEnd.IsZero() {
+		q.intervalEnd = now.Add(q.interval)
+		return
+	}
+	if now.Before(q.intervalEnd) {
+		return
+	}
+	// No waiter has left the queue during the interval, so the oldest one
+	// has been waiting at least as long as any of them would
+	if q.minDelay == math.MaxInt64 {
+		q.minDelay = 0
+		if el := q.items.Front(); el != nil {
+			q.minDelay = now.Sub(el.Value.(*waiter).enqueued)
+		}
+	}
+	q.overloaded = q.minDelay > q.target
+	q.minDelay = math.MaxInt64
+	q.intervalEnd = now.Add(q.interval)
+}
+
+func (q *codelWaitQueue) shed(now time.Time) {
+	if !q.overloaded {
+		return
+	}
+	for el := q.items.Front(); el != nil; el = q.items.Front() {
+		w := el.Value.(*waiter)
+		if now.Sub(w.enqueued) <= 2*q.target {
+			return
+		}
+		q.remove(el).reject(ErrBulkheadShed)
+	}
+}
+
+func (q *codelWaitQueue) remove(el *list.Element) *waiter {
+	w := q.items.Remove(el).(*waiter)
+	w.elem = nil
+	// A drained queue is no longer overloaded and starts measuring anew
+	if q.items.Len() == 0 {
+		q.overloaded = false
+		q.minDelay = math.MaxInt64
+		q.intervalEnd = time.Time{}
+	}
+	return w
+}
@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCoDelWaitQueue(t *testing.T) {
+	t.Run("should serve waiters in fifo order when not overloaded", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		q := NewCoDelWaitQueue(10*time.Millisecond, 100*time.Millisecond, timeProvider)
+		for seq := uint64(1); seq <= 3; seq++ {
+			q.Push(newWaiter(0, time.Time{}, seq))
+		}
+
+		// Act + Assert
+		if q.Pop().seq != 1 || q.Pop().seq != 2 || q.Pop().seq != 3 || q.overloaded {
+			t.Fail()
+		}
+	})
+
+	t.Run("should become overloaded when min delay stays above target for interval", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		q := NewCoDelWaitQueue(10*time.Millisecond, 100*time.Millisecond, timeProvider)
+		q.Push(newWaiter(0, time.Time{}, 1))
+		timeProvider.Advance(35 * time.Millisecond)
+		q.Pop() // min delay is 35ms
+		q.Push(newWaiter(0, time.Time{}, 2))
+		timeProvider.Advance(90 * time.Millisecond)
+		q.Push(newWaiter(0, time.Time{}, 3))
+		timeProvider.Advance(10 * time.Millisecond)
+
+		// Act
+		w := q.Pop()
+
+		// Assert
+		if w == nil || w.seq != 3 {
+			t.Fail()
+		}
+		if q.Len() != 0 {
+			t.Fail() // waiter #2 has been waiting longer than 2 * target
+		}
+	})
+
+	t.Run("should shed waiters that have been waiting longer than twice the target while overloaded", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		q := NewCoDelWaitQueue(10*time.Millisecond, 100*time.Millisecond, timeProvider)
+		stale := newWaiter(0, time.Time{}, 1)
+		q.Push(stale)
+		timeProvider.Advance(85 * time.Millisecond)
+		q.Push(newWaiter(0, time.Time{}, 2))
+		timeProvider.Advance(15 * time.Millisecond)
+
+		// Act
+		q.Push(newWaiter(0, time.Time{}, 3))
+		<-stale.ready
+
+		// Assert
+		if !errors.Is(stale.err, ErrBulkheadShed) || q.Len() != 2 || !q.overloaded {
+			t.Fail()
+		}
+	})
+
+	t.Run("should leave overloaded state when delays drop below target", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		q := NewCoDelWaitQueue(10*time.Millisecond, 100*time.Millisecond, timeProvider)
+		q.Push(newWaiter(0, time.Time{}, 1))
+		timeProvider.Advance(100 * time.Millisecond)
+		q.Push(newWaiter(0, time.Time{}, 2))
+
+		// Act
+		q.Pop()
+		timeProvider.Advance(100 * time.Millisecond)
+		q.Pop()
+
+		// Assert
+		if q.overloaded {
+			t.Fail()
+		}
+	})
+
+	t.Run("should leave overloaded state once queue drains", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		q := NewCoDelWaitQueue(10*time.Millisecond, 100*time.Millisecond, timeProvider)
+		q.Push(newWaiter(0, time.Time{}, 1))
+		timeProvider.Advance(85 * time.Millisecond)
+		q.Push(newWaiter(0, time.Time{}, 2))
+		timeProvider.Advance(15 * time.Millisecond)
+		q.Push(newWaiter(0, time.Time{}, 3)) // sheds waiter #1
+		overloaded := q.overloaded
+
+		// Act
+		q.Pop()
+		q.Pop()
+		drained := !q.overloaded
+		for seq := uint64(4); seq <= 5; seq++ {
+			q.Push(newWaiter(0, time.Time{}, seq))
+		}
+		timeProvider.Advance(30 * time.Millisecond)
+
+		// Assert
+		if !overloaded || !drained {
+			t.Fail()
+		}
+		if q.Pop().seq != 4 || q.Pop().seq != 5 {
+			t.Fail() // neither lifo nor shedding
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package internal
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// The algorithms below follow Netflix's concurrency-limits library.
+// Each of them receives a sample (round trip time, number of in-flight
+// requests at the moment the request was started and whether the request was
+// dropped) and returns a new estimate of the concurrency limit.
+
+type aimdLimit struct {
+	sync.Mutex
+	limit        int
+	minLimit     int
+	maxLimit     int
+	backoffRatio float64
+	timeout      time.Duration
+}
+
+func NewAimdLimit(
+	initialLimit int,
+	minLimit int,
+	maxLimit int,
+	backoffRatio float64,
+	timeout time.Duration) *aimdLimit {
+
+	validateLimits(initialLimit, minLimit, maxLimit)
+	if backoffRatio <= 0 || backoffRatio >= 1 {
+		panic("backoff ratio must be in range (0, 1)")
+	}
+	return &aimdLimit{
+		limit:        initialLimit,
+		minLimit:     minLimit,
+		maxLimit:     maxLimit,
+		backoffRatio: backoffRatio,
+		timeout:      timeout,
+	}
+}
+
+func (l *aimdLimit) Limit() int {
+	l.Lock()
+	defer l.Unlock()
+	return l.limit
+}
+
+func (l *aimdLimit) Update(rtt time.Duration, inFlight int, dropped bool) int {
+	l.Lock()
+	defer l.Unlock()
+
+	if dropped || (l.timeout > 0 && rtt > l.timeout) {
+		l.limit = clampInt(int(float64(l.limit)*l.backoffRatio), l.minLimit, l.maxLimit)
+		return l.limit
+	}
+	// Do not grow the limit when the caller does not use it (app-limited)
+	if inFlight*2 >= l.limit {
+		l.limit = clampInt(l.limit+1, l.minLimit, l.maxLimit)
+	}
+	return l.limit
+}
+
+type vegasLimit struct {
+	sync.Mutex
+	limit     float64
+	maxLimit  int
+	smoothing float64
+	rttNoLoad time.Duration
+}
+
+func NewVegasLimit(initialLimit int, maxLimit int, smoothing float64) *vegasLimit {
+	validateLimits(initialLimit, 1, maxLimit)
+	if smoothing <= 0 || smoothing > 1 {
+		panic("smoothing must be in range (0, 1]")
+	}
+	return &vegasLimit{
+		limit:     float64(initialLimit),
+		maxLimit:  maxLimit,
+		smoothing: smoothing,
+	}
+}
+
+func (l *vegasLimit) Limit() int {
+	l.Lock()
+	defer l.Unlock()
+	return int(l.limit)
+}
+
+func (l *vegasLimit) Update(rtt time.Duration, inFlight int, dropped bool) int {
+	l.Lock()
+	defer l.Unlock()
+
+	if rtt <= 0 {
+		return int(l.limit)
+	}
+	if l.rttNoLoad == 0 || rtt < l.rttNoLoad {
+		l.rttNoLoad = rtt
+		return int(l.limit)
+	}
+
+	queueSize := math.Ceil(l.limit * (1 - float64(l.rttNoLoad)/float64(rtt)))
+	threshold := math.Max(1, math.Log10(l.limit))
+	alpha := 3 * threshold
+	beta := 6 * threshold
+
+	var newLimit float64
+	switch {
+	case dropped:
+		newLimit = l.limit - threshold
+	case float64(inFlight)*2 < l.limit:
+		return int(l.limit)
+	case queueSize <= threshold:
+		newLimit = l.limit + beta
+	case queueSize < alpha:
+		newLimit = l.limit + threshold
+	case queueSize > beta:
+		newLimit = l.limit - threshold
+	default:
+		return int(l.limit)
+	}
+
+	newLimit = math.Max(1, math.Min(float64(l.maxLimit), newLimit))
+	l.limit = (1-l.smoothing)*l.limit + l.smoothing*newLimit
+	return int(l.limit)
+}
+
+type gradient2Limit struct {
+	sync.Mutex
+	limit      float64
+	minLimit   int
+	maxLimit   int
+	tolerance  float64
+	smoothing  float64
+	longRtt    float64
+	longWindow int
+	samples    int
+}
+
+func NewGradient2Limit(
+	initialLimit int,
+	minLimit int,
+	maxLimit int,
+	tolerance float64,
+	longWindow int) *gradient2Limit {
+
+	validateLimits(initialLimit, minLimit, maxLimit)
+	if tolerance < 1 {
+		panic("tolerance must be >= 1")
+	}
+	if longWindow <= 0 {
+		panic("long window must be > 0")
+	}
+	return &gradient2Limit{
+		limit:      float64(initialLimit),
+		minLimit:   minLimit,
+		maxLimit:   maxLimit,
+		tolerance:  tolerance,
+		smoothing:  0.2,
+		longWindow: longWindow,
+	}
+}
+
+func (l *gradient2Limit) Limit() int {
+	l.Lock()
+	defer l.Unlock()
+	return int(l.limit)
+}
+
+func (l *gradient2Limit) Update(rtt time.Duration, inFlight int, dropped bool) int {
+	l.Lock()
+	defer l.Unlock()
+
+	if rtt <= 0 {
+		return int(l.limit)
+	}
+	shortRtt := float64(rtt)
+	l.addLongRttSample(shortRtt)
+
+	// Speed up recovery when the long term rtt drifted far away from the current one
+	if l.longRtt/shortRtt > 2 {
+		l.longRtt *= 0.95
+	}
+	// Do not touch the limit when the caller does not use it (app-limited)
+	if float64(inFlight) < l.limit/2 && !dropped {
+		return int(l.limit)
+	}
+
+	gradient := math.Max(0.5, math.Min(1.0, l.tolerance*l.longRtt/shortRtt))
+	if dropped {
+		gradient = 0.5
+	}
+	newLimit := l.limit*gradient + math.Sqrt(l.limit)
+	newLimit = (1-l.smoothing)*l.limit + l.smoothing*newLimit
+	l.limit = math.Max(float64(l.minLimit), math.Min(float64(l.maxLimit), newLimit))
+	return int(l.limit)
+}
+
+// Exponential moving average. The first `longWindow` samples are averaged
+// to avoid bias towards the very first sample.
+func (l *gradient2Limit) addLongRttSample(sample float64) {
+	if l.samples < l.longWindow {
+		l.samples++
+		l.longRtt += (sample - l.longRtt) / float64(l.samples)
+		return
+	}
+	factor := 2 / float64(l.longWindow+1)
+	l.longRtt = l.longRtt*(1-factor) + sample*factor
+}
+
+func validateLimits(initialLimit int, minLimit int, maxLimit int) {
+	if minLimit < 1 {
+		panic("min limit must be >= 1")
+	}
+	if maxLimit < minLimit {
+		panic("max limit must be >= min limit")
+	}
+	if initialLimit < minLimit || initialLimit > maxLimit {
+		panic("initial limit must be in range [min limit, max limit]")
+	}
+}
+
+func clampInt(v int, lo int, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAimdLimit(t *testing.T) {
+	t.Run("should increase limit additively when requests succeed", func(t *testing.T) {
+		limit := NewAimdLimit(10, 1, 20, 0.9, time.Second)
+
+		if limit.Update(10*time.Millisecond, 10, false) != 11 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not increase limit when caller is app-limited", func(t *testing.T) {
+		limit := NewAimdLimit(10, 1, 20, 0.9, time.Second)
+
+		if limit.Update(10*time.Millisecond, 1, false) != 10 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should decrease limit multiplicatively when request dropped or timed out", func(t *testing.T) {
+		limit := NewAimdLimit(10, 1, 20, 0.5, time.Second)
+
+		if limit.Update(10*time.Millisecond, 10, true) != 5 {
+			t.Fail()
+		}
+		if limit.Update(2*time.Second, 5, false) != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not go beyond bounds", func(t *testing.T) {
+		limit := NewAimdLimit(2, 2, 3, 0.5, time.Second)
+
+		limit.Update(time.Millisecond, 2, false)
+		limit.Update(time.Millisecond, 3, false)
+		if limit.Limit() != 3 {
+			t.Fail()
+		}
+		limit.Update(time.Millisecond, 3, true)
+		limit.Update(time.Millisecond, 3, true)
+		if limit.Limit() != 2 {
+			t.Fail()
+		}
+	})
+}
+
+func TestVegasLimit(t *testing.T) {
+	t.Run("should increase limit when there is no queueing", func(t *testing.T) {
+		limit := NewVegasLimit(10, 100, 1)
+
+		limit.Update(10*time.Millisecond, 10, false) // no load rtt
+		limit.Update(10*time.Millisecond, 10, false)
+
+		if limit.Limit() <= 10 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should decrease limit when latency grows because of queueing", func(t *testing.T) {
+		limit := NewVegasLimit(100, 200, 1)
+
+		limit.Update(10*time.Millisecond, 100, false) // no load rtt
+		limit.Update(50*time.Millisecond, 100, false)
+
+		if limit.Limit() >= 100 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should decrease limit when request dropped", func(t *testing.T) {
+		limit := NewVegasLimit(100, 200, 1)
+
+		limit.Update(10*time.Millisecond, 100, false)
+		limit.Update(10*time.Millisecond, 100, true)
+
+		if limit.Limit() >= 100 {
+			t.Fail()
+		}
+	})
+}
+
+func TestGradient2Limit(t *testing.T) {
+	t.Run("should increase limit when latency is stable", func(t *testing.T) {
+		limit := NewGradient2Limit(16, 1, 100, 2, 10)
+
+		for i := 0; i < 3; i++ {
+			limit.Update(10*time.Millisecond, 16, false)
+		}
+
+		if limit.Limit() <= 16 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should decrease limit when latency grows", func(t *testing.T) {
+		limit := NewGradient2Limit(64, 1, 100, 1, 10)
+
+		for i := 0; i < 10; i++ {
+			limit.Update(10*time.Millisecond, 64, false)
+		}
+		before := limit.Limit()
+		limit.Update(100*time.Millisecond, before, false)
+
+		if limit.Limit() >= before {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not touch limit when caller is app-limited", func(t *testing.T) {
+		limit := NewGradient2Limit(16, 1, 100, 2, 10)
+
+		limit.Update(10*time.Millisecond, 1, false)
+
+		if limit.Limit() != 16 {
+			t.Fail()
+		}
+	})
+}
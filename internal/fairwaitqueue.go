@@ -0,0 +1,120 @@
+package internal
+
+// Fair queue keeps a separate queue per tenant and serves tenants that have
+// waiters using smooth weighted round-robin. With equal weights it is a plain
+// round-robin.
+type fairWaitQueue struct {
+	discipline  QueueDiscipline
+	tenantQueue int // 0 means that a tenant is limited by the size of the whole queue only
+	weight      func(tenant string) int
+	tenants     map[string]*tenantWaitQueue
+	active      []*tenantWaitQueue // tenants with waiters in order of arrival
+	len         int
+}
+
+type tenantWaitQueue struct {
+	tenant        string
+	queue         *heapWaitQueue
+	weight        int
+	currentWeight int
+}
+
+func NewFairWaitQueue(discipline QueueDiscipline, tenantQueue int, weight func(tenant string) int) *fairWaitQueue {
+	if tenantQueue < 0 {
+		panic("tenant queue must be >= 0")
+	}
+	return &fairWaitQueue{
+		discipline:  discipline,
+		tenantQueue: tenantQueue,
+		weight:      weight,
+		tenants:     make(map[string]*tenantWaitQueue),
+	}
+}
+
+func (q *fairWaitQueue) Len() int {
+	return q.len
+}
+
+func (q *fairWaitQueue) Push(w *waiter) bool {
+	tq, ok := q.tenants[w.tenant]
+	if !ok {
+		tq = &tenantWaitQueue{
+			tenant: w.tenant,
+			queue:  NewWaitQueue(q.discipline).(*heapWaitQueue),
+			weight: 1,
+		}
+		if q.weight != nil {
+			tq.weight = max(1, q.weight(w.tenant))
+		}
+		q.tenants[w.tenant] = tq
+		q.active = append(q.active, tq)
+	}
+	if q.tenantQueue > 0 && tq.queue.Len() >= q.tenantQueue {
+		return false
+	}
+	tq.queue.Push(w)
+	q.len++
+	return true
+}
+
+func (q *fairWaitQueue) Pop() *waiter {
+	for len(q.active) > 0 {
+		tq := q.next()
+		before := tq.queue.Len()
+		// The discipline may reject some waiters instead of returning them
+		w := tq.queue.Pop()
+		q.len -= before - tq.queue.Len()
+		if tq.queue.Len() == 0 {
+			q.deactivate(tq)
+		}
+		if w != nil {
+			return w
+		}
+	}
+	return nil
+}
+
+func (q *fairWaitQueue) Remove(w *waiter) bool {
+	tq, ok := q.tenants[w.tenant]
+	if !ok || !tq.queue.Remove(w) {
+		return false
+	}
+	q.len--
+	if tq.queue.Len() == 0 {
+		q.deactivate(tq)
+	}
+	return true
+}
+
+// Only waiters of the same tenant compete with each other for a place in the queue
+func (q *fairWaitQueue) Victim(newcomer *waiter) *waiter {
+	tq, ok := q.tenants[newcomer.tenant]
+	if !ok {
+		return nil
+	}
+	return tq.queue.Victim(newcomer)
+}
+
+func (q *fairWaitQueue) next() *tenantWaitQueue {
+	var best *tenantWaitQueue
+	total := 0
+	for _, tq := range q.active {
+		tq.currentWeight += tq.weight
+		total += tq.weight
+		if best == nil || tq.currentWeight > best.currentWeight {
+			best = tq
+		}
+	}
+	best.currentWeight -= total
+	return best
+}
+
+func (q *fairWaitQueue) deactivate(tq *tenantWaitQueue) {
+	delete(q.tenants, tq.tenant)
+	for i, active := range q.active {
+		if active == tq {
+			q.active = append(q.active[:i], q.active[i+1:]...)
+			return
+		}
+	}
+}
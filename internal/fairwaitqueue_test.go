@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFairWaitQueue(t *testing.T) {
+	t.Run("should serve tenants in round-robin order", func(t *testing.T) {
+		// Arrange
+		q := NewFairWaitQueue(QueueFIFO, 0, nil)
+		for seq, tenant := range []string{"a", "a", "a", "b", "c"} {
+			push(q, tenant, uint64(seq))
+		}
+
+		// Act
+		order := drain(q)
+
+		// Assert
+		if order != "abcaa" || q.Len() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should serve tenants according to their weights", func(t *testing.T) {
+		// Arrange
+		weight := func(tenant string) int {
+			if tenant == "a" {
+				return 2
+			}
+			return 1
+		}
+		q := NewFairWaitQueue(QueueFIFO, 0, weight)
+		for seq, tenant := range []string{"a", "a", "a", "a", "b", "b"} {
+			push(q, tenant, uint64(seq))
+		}
+
+		// Act
+		order := drain(q)
+
+		// Assert
+		if order != "abaaba" {
+			t.Fail()
+		}
+	})
+
+	t.Run("should limit queued waiters per tenant", func(t *testing.T) {
+		q := NewFairWaitQueue(QueueFIFO, 1, nil)
+
+		ok1 := push(q, "a", 1)
+		ok2 := push(q, "a", 2)
+		ok3 := push(q, "b", 3)
+
+		if !ok1 || ok2 || !ok3 || q.Len() != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should forget tenant when its last waiter removed", func(t *testing.T) {
+		q := NewFairWaitQueue(QueueFIFO, 0, nil)
+		w := newWaiter(0, time.Time{}, 1)
+		w.tenant = "a"
+		q.Push(w)
+
+		if !q.Remove(w) || q.Len() != 0 || len(q.tenants) != 0 || len(q.active) != 0 {
+			t.Fail()
+		}
+	})
+}
+
+func push(q *fairWaitQueue, tenant string, seq uint64) bool {
+	w := newWaiter(0, time.Time{}, seq)
+	w.tenant = tenant
+	return q.Push(w)
+}
+
+func drain(q *fairWaitQueue) string {
+	var order strings.Builder
+	for w := q.Pop(); w != nil; w = q.Pop() {
+		order.WriteString(w.tenant)
+	}
+	return order.String()
+}
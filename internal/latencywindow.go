@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Keeps the most recent `size` latency samples in a ring buffer and answers
+// percentile queries over them
+type latencyWindow struct {
+	sync.Mutex
+	samples []time.Duration
+	next    int
+	sorted  []time.Duration // cache of ordered samples, nil when stale
+}
+
+func NewLatencyWindow(size int) *latencyWindow {
+	if size <= 0 {
+		panic("size must be > 0")
+	}
+	return &latencyWindow{samples: make([]time.Duration, 0, size)}
+}
+
+func (w *latencyWindow) Add(sample time.Duration) {
+	w.Lock()
+	defer w.Unlock()
+	if len(w.samples) < cap(w.samples) {
+		w.samples = append(w.samples, sample)
+	} else {
+		w.samples[w.next] = sample
+	}
+	w.next = (w.next + 1) % cap(w.samples)
+	w.sorted = nil
+}
+
+func (w *latencyWindow) Len() int {
+	w.Lock()
+	defer w.Unlock()
+	return len(w.samples)
+}
+
+// Percentile uses the nearest-rank method, `p` must be in range (0, 1]
+func (w *latencyWindow) Percentile(p float64) time.Duration {
+	if p <= 0 || p > 1 {
+		panic("percentile must be in range (0, 1]")
+	}
+	w.Lock()
+	defer w.Unlock()
+	if len(w.samples) == 0 {
+		return 0
+	}
+	if w.sorted == nil {
+		w.sorted = slices.Clone(w.samples)
+		slices.Sort(w.sorted)
+	}
+	rank := int(math.Ceil(p*float64(len(w.sorted)))) - 1
+	return w.sorted[max(rank, 0)]
+}
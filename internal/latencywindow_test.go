@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyWindow(t *testing.T) {
+	t.Run("should return zero when there are no samples", func(t *testing.T) {
+		// Arrange
+		window := NewLatencyWindow(10)
+
+		// Act
+		p := window.Percentile(0.99)
+
+		// Assert
+		if p != 0 || window.Len() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should return percentile by nearest rank", func(t *testing.T) {
+		// Arrange
+		window := NewLatencyWindow(100)
+		for i := 100; i >= 1; i-- {
+			window.Add(time.Duration(i) * time.Millisecond)
+		}
+
+		// Act
+		p50 := window.Percentile(0.5)
+		p99 := window.Percentile(0.99)
+		p100 := window.Percentile(1)
+
+		// Assert
+		if p50 != 50*time.Millisecond || p99 != 99*time.Millisecond || p100 != 100*time.Millisecond {
+			t.Fail()
+		}
+	})
+
+	t.Run("should keep only the most recent samples", func(t *testing.T) {
+		// Arrange
+		window := NewLatencyWindow(3)
+
+		// Act
+		window.Add(1 * time.Second)
+		window.Add(2 * time.Millisecond)
+		window.Add(3 * time.Millisecond)
+		before := window.Percentile(1)
+		window.Add(4 * time.Millisecond)
+		after := window.Percentile(1)
+
+		// Assert
+		if before != 1*time.Second || after != 4*time.Millisecond || window.Len() != 3 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LruCache keeps at most `capacity` entries (0 means unbounded) and drops
+// entries that have not been accessed for longer than `ttl` (0 means never).
+// Eviction is lazy and happens on access. Entries for which `evictable`
+// returns false are never evicted, so the capacity may be exceeded until they
+// become evictable and the next entry is added.
+type lruCache[K comparable, V any] struct {
+	sync.Mutex
+	capacity     int
+	ttl          time.Duration
+	evictable    func(V) bool
+	items        map[K]*list.Element
+	order        *list.List // front is the most recently used entry
+	timeProvider timeProvider
+}
+
+type lruEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	lastAccess time.Time
+}
+
+func NewLruCache[K comparable, V any](
+	capacity int,
+	ttl time.Duration,
+	evictable func(V) bool,
+	timeProvider timeProvider) *lruCache[K, V] {
+
+	if capacity < 0 {
+		panic("capacity must be >= 0")
+	}
+	if ttl < 0 {
+		panic("ttl must be >= 0")
+	}
+	return &lruCache[K, V]{
+		capacity:     capacity,
+		ttl:          ttl,
+		evictable:    evictable,
+		items:        make(map[K]*list.Element),
+		order:        list.New(),
+		timeProvider: timeProvider,
+	}
+}
+
+func (c *lruCache[K, V]) GetOrAdd(key K, factory func(K) V) V {
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.timeProvider.UtcNow()
+	c.evictExpired(now)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry[K, V])
+		entry.lastAccess = now
+		c.order.MoveToFront(el)
+		return entry.value
+	}
+	entry := &lruEntry[K, V]{key: key, value: factory(key), lastAccess: now}
+	front := c.order.PushFront(entry)
+	c.items[key] = front
+	// Entries that were not evictable before may have become evictable since,
+	// so the cache shrinks back to its capacity as soon as they allow
+	for el := c.order.Back(); c.capacity > 0 && c.order.Len() > c.capacity && el != front; {
+		prev := el.Prev()
+		if c.canEvict(el) {
+			c.remove(el)
+		}
+		el = prev
+	}
+	return entry.value
+}
+
+func (c *lruCache[K, V]) Range(f func(K, V)) {
+	c.Lock()
+	defer c.Unlock()
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		entry := el.Value.(*lruEntry[K, V])
+		f(entry.key, entry.value)
+	}
+}
+
+func (c *lruCache[K, V]) Len() int {
+	c.Lock()
+	defer c.Unlock()
+	c.evictExpired(c.timeProvider.UtcNow())
+	return c.order.Len()
+}
+
+func (c *lruCache[K, V]) evictExpired(now time.Time) {
+	if c.ttl == 0 {
+		return
+	}
+	for el := c.order.Back(); el != nil; {
+		entry := el.Value.(*lruEntry[K, V])
+		if now.Sub(entry.lastAccess) < c.ttl {
+			return
+		}
+		prev := el.Prev()
+		if c.canEvict(el) {
+			c.remove(el)
+		}
+		el = prev
+	}
+}
+
+func (c *lruCache[K, V]) canEvict(el *list.Element) bool {
+	return c.evictable == nil || c.evictable(el.Value.(*lruEntry[K, V]).value)
+}
+
+func (c *lruCache[K, V]) remove(el *list.Element) {
+	entry := c.order.Remove(el).(*lruEntry[K, V])
+	delete(c.items, entry.key)
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLruCache(t *testing.T) {
+	t.Run("should create value only once per key", func(t *testing.T) {
+		// Arrange
+		cache := NewLruCache[string, int](0, 0, nil, DefaultTimeProvider)
+		var calls int
+		factory := func(key string) int {
+			calls++
+			return len(key)
+		}
+
+		// Act
+		v1 := cache.GetOrAdd("foo", factory)
+		v2 := cache.GetOrAdd("foo", factory)
+
+		// Assert
+		if v1 != 3 || v2 != 3 || calls != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should evict least recently used key when capacity exceeded", func(t *testing.T) {
+		// Arrange
+		cache := NewLruCache[string, int](2, 0, nil, DefaultTimeProvider)
+		var calls int
+		factory := func(key string) int {
+			calls++
+			return calls
+		}
+
+		// Act
+		cache.GetOrAdd("a", factory)
+		cache.GetOrAdd("b", factory)
+		cache.GetOrAdd("a", factory) // "b" becomes least recently used
+		cache.GetOrAdd("c", factory)
+		b := cache.GetOrAdd("b", factory)
+
+		// Assert
+		if b != 4 || cache.Len() != 2 {
+			t.Fail()
+		}
+		if _, ok := cache.items["a"]; ok {
+			t.Fail()
+		}
+	})
+
+	t.Run("should evict idle keys after ttl", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		cache := NewLruCache[string, int](0, 2*time.Second, nil, timeProvider)
+		factory := func(key string) int { return len(key) }
+
+		// Act
+		cache.GetOrAdd("foo", factory)
+		timeProvider.Advance(1 * time.Second)
+		cache.GetOrAdd("bazz", factory)
+		timeProvider.Advance(1 * time.Second)
+
+		// Assert
+		if cache.Len() != 1 {
+			t.Fail()
+		}
+		if _, ok := cache.items["bazz"]; !ok {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not evict entries that are not evictable", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		evictable := func(v int) bool { return v != 3 }
+		cache := NewLruCache[string, int](1, 1*time.Second, evictable, timeProvider)
+		factory := func(key string) int { return len(key) }
+
+		// Act
+		cache.GetOrAdd("foo", factory)
+		cache.GetOrAdd("ba", factory)
+		lenAfterOverflow := cache.Len()
+		timeProvider.Advance(1 * time.Second)
+
+		// Assert
+		if lenAfterOverflow != 2 || cache.Len() != 1 {
+			t.Fail()
+		}
+		if _, ok := cache.items["foo"]; !ok {
+			t.Fail()
+		}
+	})
+
+	t.Run("should shrink back to capacity once entries become evictable", func(t *testing.T) {
+		// Arrange
+		busy := true
+		evictable := func(int) bool { return !busy }
+		cache := NewLruCache[string, int](1, 0, evictable, DefaultTimeProvider)
+		factory := func(key string) int { return len(key) }
+		cache.GetOrAdd("a", factory)
+		cache.GetOrAdd("b", factory)
+		cache.GetOrAdd("c", factory)
+		lenWhileBusy := cache.Len()
+
+		// Act
+		busy = false
+		cache.GetOrAdd("d", factory)
+
+		// Assert
+		if lenWhileBusy != 3 || cache.Len() != 1 {
+			t.Fail()
+		}
+		if _, ok := cache.items["d"]; !ok {
+			t.Fail()
+		}
+	})
+}
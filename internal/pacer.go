@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrPacerQueueFull = errors.New("pacer queue is full")
+var ErrPacerSlotPastDeadline = errors.New("pacer slot is past deadline")
+
+// Pacer hands out time slots spaced evenly by `interval`. A caller that gets
+// a slot in the future has to wait for it and occupies a place in the queue
+// until it calls Dequeue. Slots given back by callers that stopped waiting
+// are handed out again before new ones.
+type pacer struct {
+	sync.Mutex
+	interval     time.Duration
+	queue        int
+	queued       int
+	nextSlot     time.Time
+	free         []time.Time // unused slots before `nextSlot`, in ascending order
+	timeProvider timeProvider
+}
+
+func NewPacer(interval time.Duration, queue int, timeProvider timeProvider) *pacer {
+	if interval <= 0 {
+		panic("interval must be > 0")
+	}
+	if queue < 0 {
+		panic("queue must be >= 0")
+	}
+	return &pacer{interval: interval, queue: queue, timeProvider: timeProvider}
+}
+
+func (p *pacer) Reserve(deadline time.Time) (time.Time, time.Duration, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	now := p.timeProvider.UtcNow()
+	// Free slots that have passed are lost, like slots while idle
+	for len(p.free) > 0 && p.free[0].Before(now) {
+		p.free = p.free[1:]
+	}
+	reused := len(p.free) > 0
+	slot := p.nextSlot
+	if reused {
+		slot = p.free[0]
+	}
+	if slot.Before(now) {
+		slot = now
+	}
+	delay := slot.Sub(now)
+	if delay > 0 && p.queued >= p.queue {
+		return time.Time{}, 0, ErrPacerQueueFull
+	}
+	if !deadline.IsZero() && slot.After(deadline) {
+		return time.Time{}, 0, ErrPacerSlotPastDeadline
+	}
+	if reused {
+		p.free = p.free[1:]
+	} else {
+		p.nextSlot = slot.Add(p.interval)
+	}
+	if delay > 0 {
+		p.queued++
+	}
+	return slot, delay, nil
+}
+
+// Dequeue must be called once by every caller that got a slot in the future.
+// An unused slot is given back.
+func (p *pacer) Dequeue(slot time.Time, used bool) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.queued--
+	if used {
+		return
+	}
+	i, _ := slices.BinarySearchFunc(p.free, slot, time.Time.Compare)
+	p.free = slices.Insert(p.free, i, slot)
+	// Trailing free slots shrink the schedule instead
+	for len(p.free) > 0 && p.nextSlot.Equal(p.free[len(p.free)-1].Add(p.interval)) {
+		p.nextSlot = p.free[len(p.free)-1]
+		p.free = p.free[:len(p.free)-1]
+	}
+}
+
+func (p *pacer) Queued() int {
+	p.Lock()
+	defer p.Unlock()
+	return p.queued
+}
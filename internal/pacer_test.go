@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPacer(t *testing.T) {
+	t.Run("should space slots evenly", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		pacer := NewPacer(100*time.Millisecond, 10, timeProvider)
+
+		// Act
+		_, delay1, _ := pacer.Reserve(time.Time{})
+		_, delay2, _ := pacer.Reserve(time.Time{})
+		_, delay3, _ := pacer.Reserve(time.Time{})
+
+		// Assert
+		if delay1 != 0 || delay2 != 100*time.Millisecond || delay3 != 200*time.Millisecond {
+			t.Fail()
+		}
+		if pacer.Queued() != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not accumulate slots while idle", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		pacer := NewPacer(100*time.Millisecond, 10, timeProvider)
+
+		// Act
+		pacer.Reserve(time.Time{})
+		timeProvider.Advance(1 * time.Second)
+		_, delay1, _ := pacer.Reserve(time.Time{})
+		_, delay2, _ := pacer.Reserve(time.Time{})
+
+		// Assert
+		if delay1 != 0 || delay2 != 100*time.Millisecond {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject when queue is full", func(t *testing.T) {
+		pacer := NewPacer(100*time.Millisecond, 1, NewFakeTimeProvider())
+
+		pacer.Reserve(time.Time{})
+		pacer.Reserve(time.Time{})
+		_, _, err := pacer.Reserve(time.Time{})
+
+		if !errors.Is(err, ErrPacerQueueFull) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject when slot is past deadline", func(t *testing.T) {
+		timeProvider := NewFakeTimeProvider()
+		pacer := NewPacer(100*time.Millisecond, 10, timeProvider)
+
+		pacer.Reserve(time.Time{})
+		_, _, err := pacer.Reserve(timeProvider.UtcNow().Add(50 * time.Millisecond))
+
+		if !errors.Is(err, ErrPacerSlotPastDeadline) || pacer.Queued() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should give back last unused slot", func(t *testing.T) {
+		pacer := NewPacer(100*time.Millisecond, 10, NewFakeTimeProvider())
+
+		pacer.Reserve(time.Time{})
+		slot, _, _ := pacer.Reserve(time.Time{})
+		pacer.Dequeue(slot, false)
+		_, delay, _ := pacer.Reserve(time.Time{})
+
+		if delay != 100*time.Millisecond || pacer.Queued() != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should hand out slot given back from the middle of the queue", func(t *testing.T) {
+		pacer := NewPacer(100*time.Millisecond, 10, NewFakeTimeProvider())
+
+		pacer.Reserve(time.Time{})
+		middle, _, _ := pacer.Reserve(time.Time{})
+		pacer.Reserve(time.Time{})
+		pacer.Dequeue(middle, false)
+		reused, delay, _ := pacer.Reserve(time.Time{})
+		_, next, _ := pacer.Reserve(time.Time{})
+
+		if !reused.Equal(middle) || delay != 100*time.Millisecond || next != 300*time.Millisecond {
+			t.Fail()
+		}
+		if pacer.Queued() != 3 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should shrink schedule when slots are given back from its end", func(t *testing.T) {
+		pacer := NewPacer(100*time.Millisecond, 10, NewFakeTimeProvider())
+
+		pacer.Reserve(time.Time{})
+		second, _, _ := pacer.Reserve(time.Time{})
+		third, _, _ := pacer.Reserve(time.Time{})
+		pacer.Dequeue(second, false)
+		pacer.Dequeue(third, false)
+		_, delay1, _ := pacer.Reserve(time.Time{})
+		_, delay2, _ := pacer.Reserve(time.Time{})
+
+		if delay1 != 100*time.Millisecond || delay2 != 200*time.Millisecond || len(pacer.free) != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not reuse free slot that has passed", func(t *testing.T) {
+		timeProvider := NewFakeTimeProvider()
+		pacer := NewPacer(100*time.Millisecond, 10, timeProvider)
+
+		pacer.Reserve(time.Time{})
+		middle, _, _ := pacer.Reserve(time.Time{})
+		pacer.Reserve(time.Time{})
+		pacer.Dequeue(middle, false)
+		timeProvider.Advance(150 * time.Millisecond)
+		_, delay, _ := pacer.Reserve(time.Time{})
+
+		if delay != 150*time.Millisecond {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Partitions keeps a value per key in an LRU cache. A value is marked as used
+// from the moment it is acquired until it is released, and used values are never
+// evicted. Lookup and marking happen under the same lock, so that a value cannot
+// be evicted between lookup and use.
+type partitions[K comparable, V any] struct {
+	sync.Mutex
+	cache *lruCache[K, *partition[V]]
+}
+
+type partition[V any] struct {
+	value V
+	users atomic.Int64
+}
+
+// `idle` may further restrict eviction of values that are not in use, nil means no restriction
+func NewPartitions[K comparable, V any](
+	capacity int,
+	ttl time.Duration,
+	idle func(V) bool,
+	timeProvider timeProvider) *partitions[K, V] {
+
+	evictable := func(p *partition[V]) bool {
+		return p.users.Load() == 0 && (idle == nil || idle(p.value))
+	}
+	return &partitions[K, V]{
+		cache: NewLruCache[K, *partition[V]](capacity, ttl, evictable, timeProvider),
+	}
+}
+
+// Acquire returns the value of the key, creating it if needed, along with the
+// function that releases it
+func (p *partitions[K, V]) Acquire(key K, factory func(K) V) (V, func()) {
+	p.Lock()
+	defer p.Unlock()
+	part := p.cache.GetOrAdd(key, func(k K) *partition[V] {
+		return &partition[V]{value: factory(k)}
+	})
+	part.users.Add(1)
+	return part.value, func() { part.users.Add(-1) }
+}
+
+func (p *partitions[K, V]) Range(f func(K, V)) {
+	p.cache.Range(func(k K, part *partition[V]) {
+		f(k, part.value)
+	})
+}
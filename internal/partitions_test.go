@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"testing"
+)
+
+func TestPartitions(t *testing.T) {
+	t.Run("should not evict acquired value", func(t *testing.T) {
+		// Arrange
+		partitions := NewPartitions[string, int](1, 0, nil, DefaultTimeProvider)
+		factory := func(key string) int { return len(key) }
+
+		// Act
+		_, release := partitions.Acquire("foo", factory)
+		partitions.Acquire("ba", factory)
+		lenWhileUsed := partitions.cache.Len()
+		release()
+		partitions.Acquire("x", factory)
+
+		// Assert
+		if lenWhileUsed != 2 {
+			t.Fail()
+		}
+		if _, ok := partitions.cache.items["foo"]; ok {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not evict value that is not idle", func(t *testing.T) {
+		// Arrange
+		idle := func(v int) bool { return v != 3 }
+		partitions := NewPartitions[string, int](1, 0, idle, DefaultTimeProvider)
+		factory := func(key string) int { return len(key) }
+
+		// Act
+		_, release := partitions.Acquire("foo", factory)
+		release()
+		partitions.Acquire("ba", factory)
+
+		// Assert
+		if _, ok := partitions.cache.items["foo"]; !ok {
+			t.Fail()
+		}
+	})
+}
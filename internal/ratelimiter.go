@@ -67,6 +67,18 @@ func (rl lockFreeTokenBucketRateLimiter) Try() (bool, time.Duration) {
 	}
 }
 
+// FreeTokens reports the number of tokens available right now without taking any
+func (rl lockFreeTokenBucketRateLimiter) FreeTokens() int64 {
+	if tokens := rl.freeTokens.Load(); tokens > 0 {
+		return tokens
+	}
+	delta := rl.timeProvider.UtcNow().UnixNano() - rl.tokenGenTime.Load()
+	if delta < 0 {
+		return 0
+	}
+	return minInt64(rl.capacity, 1+delta/rl.tokenPerUnit.Nanoseconds())
+}
+
 func minInt64(a int64, b int64) int64 {
 	if a < b {
 		return a
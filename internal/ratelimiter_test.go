@@ -9,7 +9,7 @@ func TestRateLimiter(t *testing.T) {
 	t.Run("should not increase free tokens beyond capacity", func(t *testing.T) {
 		// Arrange
 		utcNow := time.Now().UTC()
-		timeProvider := fakeTimeProvider{utcNow}
+		timeProvider := fakeTimeProvider{now: utcNow}
 		rateLimiter := NewLockFreeTokenBucketRateLimiter(1*time.Second, 5, &timeProvider)
 
 		// Act
@@ -26,7 +26,7 @@ func TestRateLimiter(t *testing.T) {
 	t.Run("should cacl next token generation time correctly when there is a fraction", func(t *testing.T) {
 		// Arrange
 		utcNow := time.Now().UTC()
-		timeProvider := fakeTimeProvider{utcNow}
+		timeProvider := fakeTimeProvider{now: utcNow}
 		rateLimiter := NewLockFreeTokenBucketRateLimiter(1*time.Second, 30, &timeProvider)
 
 		// Act
@@ -47,7 +47,7 @@ func TestRateLimiter(t *testing.T) {
 	t.Run("should increase free tokens and cacl next token generation time", func(t *testing.T) {
 		// Arrange
 		utcNow := time.Now().UTC()
-		timeProvider := fakeTimeProvider{utcNow}
+		timeProvider := fakeTimeProvider{now: utcNow}
 		rateLimiter := NewLockFreeTokenBucketRateLimiter(1*time.Second, 30, &timeProvider)
 
 		// Act
@@ -64,6 +64,24 @@ func TestRateLimiter(t *testing.T) {
 		}
 	})
 
+	t.Run("should report free tokens that would be generated by now", func(t *testing.T) {
+		// Arrange
+		utcNow := time.Now().UTC()
+		timeProvider := fakeTimeProvider{now: utcNow}
+		rateLimiter := NewLockFreeTokenBucketRateLimiter(1*time.Second, 5, &timeProvider)
+
+		// Act
+		exhaustFreeTokens(rateLimiter)
+		before := rateLimiter.FreeTokens()
+		timeProvider.Advance(2 * time.Second)
+		after := rateLimiter.FreeTokens()
+
+		// Assert
+		if before != 0 || after != 2 {
+			t.Fail()
+		}
+	})
+
 	t.Run("should descrease free tokens on each try", func(t *testing.T) {
 		rateLimiter := NewLockFreeTokenBucketRateLimiter(1*time.Second, 2, DefaultTimeProvider)
 		rateLimiter.Try()
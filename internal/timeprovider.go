@@ -1,11 +1,20 @@
 package internal
 
-import "time"
+import (
+	"slices"
+	"sync"
+	"time"
+)
 
 type timeProvider interface {
 	UtcNow() time.Time
 }
 
+// A time provider may also take over scheduling of delayed work
+type scheduler interface {
+	AfterFunc(d time.Duration, f func())
+}
+
 type timeProviderFunc func() time.Time
 
 func (f timeProviderFunc) UtcNow() time.Time {
@@ -16,8 +25,25 @@ var DefaultTimeProvider timeProviderFunc = func() time.Time {
 	return time.Now().UTC()
 }
 
+// AfterFunc runs `f` once `d` has passed according to the time provider.
+// Time providers that cannot schedule fall back to the system timer.
+func AfterFunc(tp timeProvider, d time.Duration, f func()) {
+	if s, ok := tp.(scheduler); ok {
+		s.AfterFunc(d, f)
+		return
+	}
+	time.AfterFunc(d, f)
+}
+
+type fakeTimer struct {
+	at time.Time
+	f  func()
+}
+
 type fakeTimeProvider struct {
-	now time.Time
+	m      sync.Mutex
+	now    time.Time
+	timers []fakeTimer
 }
 
 func NewFakeTimeProvider() *fakeTimeProvider {
@@ -25,11 +51,37 @@ func NewFakeTimeProvider() *fakeTimeProvider {
 }
 
 func (tp *fakeTimeProvider) UtcNow() time.Time {
+	tp.m.Lock()
+	defer tp.m.Unlock()
 	return tp.now
 }
 
+// Advance runs timers that become due, in order, on the calling goroutine
 func (tp *fakeTimeProvider) Advance(delta time.Duration) time.Time {
+	tp.m.Lock()
 	prev := tp.now
 	tp.now = tp.now.Add(delta)
+	var due []fakeTimer
+	tp.timers = slices.DeleteFunc(tp.timers, func(t fakeTimer) bool {
+		if t.at.After(tp.now) {
+			return false
+		}
+		due = append(due, t)
+		return true
+	})
+	tp.m.Unlock()
+
+	slices.SortStableFunc(due, func(a, b fakeTimer) int {
+		return a.at.Compare(b.at)
+	})
+	for _, t := range due {
+		t.f()
+	}
 	return prev
 }
+
+func (tp *fakeTimeProvider) AfterFunc(d time.Duration, f func()) {
+	tp.m.Lock()
+	defer tp.m.Unlock()
+	tp.timers = append(tp.timers, fakeTimer{at: tp.now.Add(d), f: f})
+}
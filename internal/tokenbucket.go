@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"sync"
+	"time"
+)
+
+// Unlike lockFreeTokenBucketRateLimiter this implementation is able to take
+// several tokens at once, which requires a lock to keep the bucket consistent.
+type tokenBucketRateLimiter struct {
+	sync.Mutex
+	freeTokens   int64
+	tokenGenTime time.Time // time the last generated token appeared in the bucket
+	capacity     int64
+	tokenPerUnit time.Duration
+	timeProvider timeProvider
+}
+
+func NewTokenBucketRateLimiter(
+	tokenPerUnit time.Duration,
+	capacity int64,
+	timeProvider timeProvider) *tokenBucketRateLimiter {
+
+	if tokenPerUnit <= 0 {
+		panic("token per unit must be > 0")
+	}
+	if capacity < 0 {
+		panic("capacity must be >= 0")
+	}
+	return &tokenBucketRateLimiter{
+		freeTokens:   capacity,
+		tokenGenTime: timeProvider.UtcNow(),
+		capacity:     capacity,
+		tokenPerUnit: tokenPerUnit,
+		timeProvider: timeProvider,
+	}
+}
+
+func (rl *tokenBucketRateLimiter) TryN(n int64) (bool, time.Duration) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.timeProvider.UtcNow()
+	rl.refill(now)
+	if rl.freeTokens >= n {
+		rl.freeTokens -= n
+		return true, 0
+	}
+	missing := time.Duration(n-rl.freeTokens) * rl.tokenPerUnit
+	return false, missing - now.Sub(rl.tokenGenTime)
+}
+
+func (rl *tokenBucketRateLimiter) refill(now time.Time) {
+	tokens := int64(now.Sub(rl.tokenGenTime) / rl.tokenPerUnit)
+	if tokens <= 0 {
+		return
+	}
+	if rl.freeTokens+tokens >= rl.capacity {
+		rl.freeTokens = rl.capacity
+		rl.tokenGenTime = now
+		return
+	}
+	rl.freeTokens += tokens
+	rl.tokenGenTime = rl.tokenGenTime.Add(time.Duration(tokens) * rl.tokenPerUnit)
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	t.Run("should take several tokens at once", func(t *testing.T) {
+		rateLimiter := NewTokenBucketRateLimiter(1*time.Second, 10, NewFakeTimeProvider())
+
+		ok1, _ := rateLimiter.TryN(7)
+		ok2, _ := rateLimiter.TryN(4)
+		ok3, _ := rateLimiter.TryN(3)
+
+		if !ok1 || ok2 || !ok3 || rateLimiter.freeTokens != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not take tokens when there are not enough of them", func(t *testing.T) {
+		rateLimiter := NewTokenBucketRateLimiter(1*time.Second, 10, NewFakeTimeProvider())
+
+		rateLimiter.TryN(8)
+		ok, _ := rateLimiter.TryN(3)
+
+		if ok || rateLimiter.freeTokens != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should report time left until enough tokens are generated", func(t *testing.T) {
+		// Arrange
+		timeProvider := NewFakeTimeProvider()
+		rateLimiter := NewTokenBucketRateLimiter(1*time.Second, 10, timeProvider)
+
+		// Act
+		rateLimiter.TryN(10)
+		timeProvider.Advance(1500 * time.Millisecond)
+		ok, retryAfter := rateLimiter.TryN(3)
+
+		// Assert
+		if ok || retryAfter != 1500*time.Millisecond {
+			t.Fail()
+		}
+		timeProvider.Advance(retryAfter)
+		if ok, _ := rateLimiter.TryN(3); !ok {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not increase free tokens beyond capacity", func(t *testing.T) {
+		timeProvider := NewFakeTimeProvider()
+		rateLimiter := NewTokenBucketRateLimiter(1*time.Second, 5, timeProvider)
+
+		rateLimiter.TryN(5)
+		timeProvider.Advance(100 * time.Second)
+		rateLimiter.TryN(1)
+
+		if rateLimiter.freeTokens != 4 {
+			t.Fail()
+		}
+	})
+}
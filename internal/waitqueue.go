@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"container/heap"
+	"container/list"
+	"context"
+	"time"
+)
+
+type waiter struct {
+	ready    chan struct{}
+	err      error // reason of rejection, must be set before `ready` is closed
+	priority int
+	deadline time.Time
+	tenant   string
+	seq      uint64
+	enqueued time.Time
+	index    int           // position in a heap based queue
+	elem     *list.Element // position in a list based queue
+}
+
+func newWaiter(priority int, deadline time.Time, seq uint64) *waiter {
+	return &waiter{
+		ready:    make(chan struct{}),
+		priority: priority,
+		deadline: deadline,
+		seq:      seq,
+		index:    -1,
+	}
+}
+
+func (w *waiter) admit() {
+	close(w.ready)
+}
+
+func (w *waiter) reject(err error) {
+	w.err = err
+	close(w.ready)
+}
+
+type QueueDiscipline int
+
+const (
+	QueueFIFO QueueDiscipline = 0
+	QueueLIFO QueueDiscipline = 1
+	QueueEDF  QueueDiscipline = 2
+)
+
+func NewWaitQueue(discipline QueueDiscipline) waitQueue {
+	switch discipline {
+	case QueueFIFO:
+		return NewFifoWaitQueue()
+	case QueueLIFO:
+		return NewLifoWaitQueue()
+	case QueueEDF:
+		return NewEdfWaitQueue()
+	}
+	panic("not supported")
+}
+
+type waitQueue interface {
+	Len() int
+	Push(w *waiter) bool
+	Pop() *waiter
+	Remove(w *waiter) bool
+}
+
+// A queue that is able to make room for a newcomer by evicting one of the queued waiters
+type evictingWaitQueue interface {
+	waitQueue
+	Victim(newcomer *waiter) *waiter
+}
+
+// Waiters with higher priority always go first. The discipline of the queue
+// decides the order among waiters with equal priority.
+type heapWaitQueue struct {
+	items         []*waiter
+	less          func(a *waiter, b *waiter) bool
+	skipDeadlined bool
+}
+
+func NewFifoWaitQueue() *heapWaitQueue {
+	return &heapWaitQueue{less: func(a *waiter, b *waiter) bool {
+		return a.seq < b.seq
+	}}
+}
+
+func NewLifoWaitQueue() *heapWaitQueue {
+	return &heapWaitQueue{less: func(a *waiter, b *waiter) bool {
+		return a.seq > b.seq
+	}}
+}
+
+// Earliest deadline first. Waiters without deadline go last in FIFO order.
+// Waiters whose deadline has already passed are rejected instead of being admitted.
+func NewEdfWaitQueue() *heapWaitQueue {
+	return &heapWaitQueue{
+		less: func(a *waiter, b *waiter) bool {
+			if a.deadline.IsZero() != b.deadline.IsZero() {
+				return b.deadline.IsZero()
+			}
+			if !a.deadline.Equal(b.deadline) {
+				return a.deadline.Before(b.deadline)
+			}
+			return a.seq < b.seq
+		},
+		skipDeadlined: true,
+	}
+}
+
+func (q *heapWaitQueue) Len() int {
+	return len(q.items)
+}
+
+func (q *heapWaitQueue) Push(w *waiter) bool {
+	heap.Push((*waiterHeap)(q), w)
+	return true
+}
+
+func (q *heapWaitQueue) Pop() *waiter {
+	now := time.Now()
+	for len(q.items) > 0 {
+		w := heap.Pop((*waiterHeap)(q)).(*waiter)
+		if q.skipDeadlined && !w.deadline.IsZero() && !now.Before(w.deadline) {
+			w.reject(context.DeadlineExceeded)
+			continue
+		}
+		return w
+	}
+	return nil
+}
+
+func (q *heapWaitQueue) Remove(w *waiter) bool {
+	if w.index < 0 || w.index >= len(q.items) || q.items[w.index] != w {
+		return false
+	}
+	heap.Remove((*waiterHeap)(q), w.index)
+	return true
+}
+
+// Victim is the waiter that would be served last, provided that its priority
+// is lower than the priority of the newcomer
+func (q *heapWaitQueue) Victim(newcomer *waiter) *waiter {
+	h := (*waiterHeap)(q)
+	victim := -1
+	for i := range q.items {
+		if victim < 0 || h.Less(victim, i) {
+			victim = i
+		}
+	}
+	if victim < 0 || q.items[victim].priority >= newcomer.priority {
+		return nil
+	}
+	return q.items[victim]
+}
+
+type waiterHeap heapWaitQueue
+
+func (h *waiterHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *waiterHeap) Less(i, j int) bool {
+	if h.items[i].priority != h.items[j].priority {
+		return h.items[i].priority > h.items[j].priority
+	}
+	return h.less(h.items[i], h.items[j])
+}
+
+func (h *waiterHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *waiterHeap) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(h.items)
+	h.items = append(h.items, w)
+}
+
+func (h *waiterHeap) Pop() any {
+	n := len(h.items)
+	w := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	w.index = -1
+	return w
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitQueue(t *testing.T) {
+	t.Run("fifo queue should serve waiters in arrival order", func(t *testing.T) {
+		q := NewFifoWaitQueue()
+		for seq := uint64(1); seq <= 3; seq++ {
+			q.Push(newWaiter(0, time.Time{}, seq))
+		}
+
+		if q.Pop().seq != 1 || q.Pop().seq != 2 || q.Pop().seq != 3 {
+			t.Fail()
+		}
+	})
+
+	t.Run("lifo queue should serve most recent waiter first", func(t *testing.T) {
+		q := NewLifoWaitQueue()
+		for seq := uint64(1); seq <= 3; seq++ {
+			q.Push(newWaiter(0, time.Time{}, seq))
+		}
+
+		if q.Pop().seq != 3 || q.Pop().seq != 2 || q.Pop().seq != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("edf queue should serve waiter with earliest deadline first", func(t *testing.T) {
+		now := time.Now()
+		q := NewEdfWaitQueue()
+		q.Push(newWaiter(0, time.Time{}, 1))
+		q.Push(newWaiter(0, now.Add(2*time.Hour), 2))
+		q.Push(newWaiter(0, now.Add(1*time.Hour), 3))
+
+		if q.Pop().seq != 3 || q.Pop().seq != 2 || q.Pop().seq != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("edf queue should reject waiters whose deadline has passed", func(t *testing.T) {
+		// Arrange
+		q := NewEdfWaitQueue()
+		expired := newWaiter(0, time.Now().Add(-1*time.Second), 1)
+		q.Push(expired)
+		q.Push(newWaiter(0, time.Time{}, 2))
+
+		// Act
+		w := q.Pop()
+		<-expired.ready
+
+		// Assert
+		if w.seq != 2 || !errors.Is(expired.err, context.DeadlineExceeded) {
+			t.Fail()
+		}
+	})
+
+	t.Run("priority should take precedence over discipline", func(t *testing.T) {
+		q := NewLifoWaitQueue()
+		q.Push(newWaiter(1, time.Time{}, 1))
+		q.Push(newWaiter(0, time.Time{}, 2))
+
+		if q.Pop().seq != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("victim should be waiter served last with lower priority than newcomer", func(t *testing.T) {
+		q := NewFifoWaitQueue()
+		q.Push(newWaiter(0, time.Time{}, 1))
+		q.Push(newWaiter(0, time.Time{}, 2))
+		q.Push(newWaiter(1, time.Time{}, 3))
+
+		if q.Victim(newWaiter(1, time.Time{}, 4)).seq != 2 || q.Victim(newWaiter(0, time.Time{}, 4)) != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("should remove waiter from the middle of the queue", func(t *testing.T) {
+		q := NewFifoWaitQueue()
+		w := newWaiter(0, time.Time{}, 2)
+		q.Push(newWaiter(0, time.Time{}, 1))
+		q.Push(w)
+		q.Push(newWaiter(0, time.Time{}, 3))
+
+		if !q.Remove(w) || q.Remove(w) || q.Pop().seq != 1 || q.Pop().seq != 3 {
+			t.Fail()
+		}
+	})
+}
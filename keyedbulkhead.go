@@ -0,0 +1,117 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+type KeyedBulkheadOptions[S any, K comparable] struct {
+	Key KeyFunc[S, K]
+	// Options of the bulkhead created for each key
+	Partition BulkheadOptions[S]
+	// Limits the number of in-flight and queued calls across all keys. Zero means no limit.
+	MaxTotal int
+	// Limits the number of partitions. Idle partitions are evicted first,
+	// busy ones are never evicted. Zero means no limit.
+	MaxPartitions int
+	// Evicts partitions that have not been used for the given duration. Zero means never.
+	IdleTTL time.Duration
+}
+
+// A value per key that is never evicted while acquired
+type partitionSet[K comparable, V any] interface {
+	Acquire(K, func(K) V) (V, func())
+	Range(func(K, V))
+}
+
+type KeyedBulkhead[S any, T any, K comparable] struct {
+	key        KeyFunc[S, K]
+	options    BulkheadOptions[S]
+	partitions partitionSet[K, *Bulkhead[S, T]]
+	maxTotal   int64
+	total      atomic.Int64
+	counters   policyCounters
+	gate       policyGate
+}
+
+func NewKeyedBulkheadPolicy[S any, T any, K comparable](options KeyedBulkheadOptions[S, K]) Policy[S, T] {
+	return NewKeyedBulkhead[S, T](options).Policy()
+}
+
+func NewKeyedBulkhead[S any, T any, K comparable](options KeyedBulkheadOptions[S, K]) *KeyedBulkhead[S, T, K] {
+	if options.MaxTotal < 0 {
+		panic("max total must be >= 0")
+	}
+	return &KeyedBulkhead[S, T, K]{
+		key:     options.Key,
+		options: options.Partition,
+		partitions: internal.NewPartitions[K, *Bulkhead[S, T]](
+			options.MaxPartitions,
+			options.IdleTTL,
+			nil,
+			internal.DefaultTimeProvider),
+		maxTotal: int64(options.MaxTotal),
+	}
+}
+
+func (kb *KeyedBulkhead[S, T, K]) Policy() Policy[S, T] {
+	return gated(&kb.gate, &kb.counters, func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		var zero T
+		if total := kb.total.Add(1); kb.maxTotal > 0 && total > kb.maxTotal {
+			kb.total.Add(-1)
+			kb.counters.reject()
+			return zero, ErrBulkheadRejected
+		}
+		defer kb.total.Add(-1)
+
+		bulkhead, release := kb.partitions.Acquire(kb.key(ctx, s), kb.newPartition)
+		defer release()
+		return bulkhead.Policy()(ctx, func(ctx context.Context, s S) (T, error) {
+			return countedCall(&kb.counters, ctx, f, s)
+		}, s)
+	})
+}
+
+func (kb *KeyedBulkhead[S, T, K]) PartitionStats() map[K]Stats {
+	stats := make(map[K]Stats)
+	kb.partitions.Range(func(k K, bulkhead *Bulkhead[S, T]) {
+		stats[k] = bulkhead.Stats()
+	})
+	return stats
+}
+
+// Stats returns a snapshot across all partitions. Rejections made by
+// partitions themselves are reported by PartitionStats only.
+func (kb *KeyedBulkhead[S, T, K]) Stats() Stats {
+	stats := kb.counters.snapshot()
+	kb.partitions.Range(func(_ K, bulkhead *Bulkhead[S, T]) {
+		stats.Queued += bulkhead.Stats().Queued
+	})
+	return stats
+}
+
+// Drain stops admitting new calls and lets queued and in-flight calls of every partition complete
+func (kb *KeyedBulkhead[S, T, K]) Drain(ctx context.Context) error {
+	kb.gate.close()
+	var drainers []Drainer
+	kb.partitions.Range(func(_ K, bulkhead *Bulkhead[S, T]) {
+		drainers = append(drainers, bulkhead)
+	})
+	return errors.Join(DrainAll(ctx, drainers...), kb.gate.drain(ctx))
+}
+
+// Close rejects queued calls of every partition and lets in-flight calls complete
+func (kb *KeyedBulkhead[S, T, K]) Close() {
+	kb.gate.close()
+	kb.partitions.Range(func(_ K, bulkhead *Bulkhead[S, T]) {
+		bulkhead.Close()
+	})
+}
+
+func (kb *KeyedBulkhead[S, T, K]) newPartition(K) *Bulkhead[S, T] {
+	return NewBulkheadWithOptions[S, T](kb.options)
+}
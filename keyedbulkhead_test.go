@@ -0,0 +1,222 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeyedBulkhead(t *testing.T) {
+	key := func(_ context.Context, s string) string { return s }
+
+	t.Run("should isolate partitions from each other", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewKeyedBulkhead[string, int](KeyedBulkheadOptions[string, string]{
+			Key:       key,
+			Partition: BulkheadOptions[string]{Concurrency: 1},
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		_, errSameKey := policy(context.Background(), f, "foo")
+		_, errOtherKey := policy(context.Background(), f, "bazz")
+		stats := bulkhead.PartitionStats()
+		close(barrier)
+		<-done
+
+		// Assert
+		if !errors.Is(errSameKey, ErrBulkheadRejected) || errOtherKey != nil {
+			t.Fail()
+		}
+		if stats["foo"].InFlight != 1 || stats["foo"].Rejected != 1 || stats["bazz"].Accepted != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should enforce global cap across all partitions", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewKeyedBulkhead[string, int](KeyedBulkheadOptions[string, string]{
+			Key:       key,
+			Partition: BulkheadOptions[string]{Concurrency: 1},
+			MaxTotal:  1,
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+
+		// Act
+		_, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "bazz")
+		stats := bulkhead.Stats()
+		close(barrier)
+		<-done
+
+		// Assert
+		if !errors.Is(err, ErrBulkheadRejected) || stats.Rejected != 1 || stats.InFlight != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should evict idle partitions", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewKeyedBulkhead[string, int](KeyedBulkheadOptions[string, string]{
+			Key:           key,
+			Partition:     BulkheadOptions[string]{Concurrency: 1},
+			MaxPartitions: 1,
+		})
+		policy := bulkhead.Policy()
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		policy(context.Background(), f, "foo")
+		policy(context.Background(), f, "bazz")
+		stats := bulkhead.PartitionStats()
+
+		// Assert
+		if _, ok := stats["foo"]; ok || len(stats) != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not evict busy partitions", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewKeyedBulkhead[string, int](KeyedBulkheadOptions[string, string]{
+			Key:           key,
+			Partition:     BulkheadOptions[string]{Concurrency: 1},
+			MaxPartitions: 1,
+			IdleTTL:       1 * time.Millisecond,
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+		time.Sleep(10 * time.Millisecond)
+
+		// Act
+		policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "bazz")
+		stats := bulkhead.PartitionStats()
+		close(barrier)
+		<-done
+
+		// Assert
+		if stats["foo"].InFlight != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should drain every partition", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewKeyedBulkhead[string, int](KeyedBulkheadOptions[string, string]{
+			Key:       key,
+			Partition: BulkheadOptions[string]{Concurrency: 1},
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		results := make(chan error)
+		go func() {
+			_, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+			results <- err
+		}()
+		<-started
+		time.AfterFunc(20*time.Millisecond, func() { close(barrier) })
+
+		// Act
+		drainErr := DrainAll(context.Background(), bulkhead)
+		inFlightErr := <-results
+		_, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "bazz")
+
+		// Assert
+		if drainErr != nil || inFlightErr != nil || !errors.Is(err, ErrPolicyClosed) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject queued calls of every partition on close", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewKeyedBulkhead[string, int](KeyedBulkheadOptions[string, string]{
+			Key:       key,
+			Partition: BulkheadOptions[string]{Concurrency: 1, Queue: 1},
+		})
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				close(started)
+				<-barrier
+				return len(s), nil
+			}, "foo")
+		}()
+		<-started
+		queued := make(chan error)
+		go func() {
+			_, err := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+				return len(s), nil
+			}, "foo")
+			queued <- err
+		}()
+		for bulkhead.Stats().Queued != 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Act
+		bulkhead.Close()
+		err := <-queued
+		close(barrier)
+		<-done
+
+		// Assert
+		if !errors.Is(err, ErrPolicyClosed) {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package resilience
+
+import (
+	"context"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+type DebounceMode int
+
+const (
+	// Runs the first call of a burst and rejects the rest, see NewDebouncer
+	DebounceLeading DebounceMode = 0
+	// Runs the last call of a burst and shares its outcome, see NewTrailingDebouncer
+	DebounceTrailing DebounceMode = 1
+	// Runs the first call of a burst and replays its outcome to the rest, see NewReplayDebouncer
+	DebounceReplay DebounceMode = 2
+)
+
+type KeyedDebounceOptions[S any, K comparable] struct {
+	Key      KeyFunc[S, K]
+	Duration time.Duration
+	Mode     DebounceMode
+	// Applies to the trailing mode only
+	MaxWait time.Duration
+	// Limits the number of tracked keys. Keys with calls in progress or with an
+	// open debounce window are never evicted. Zero means no limit.
+	MaxKeys int
+	// Evicts keys that have not been used for the given duration, must not be
+	// shorter than Duration. Zero means never.
+	IdleTTL time.Duration
+}
+
+func NewKeyedDebouncePolicy[S any, T any, K comparable](options KeyedDebounceOptions[S, K]) Policy[S, T] {
+	if options.IdleTTL > 0 && options.IdleTTL < options.Duration {
+		panic("idle ttl must be >= duration")
+	}
+	if options.Mode < DebounceLeading || options.Mode > DebounceReplay {
+		panic("not supported")
+	}
+	newDebouncer := func(K) *Debouncer[S, T] {
+		switch options.Mode {
+		case DebounceLeading:
+			return NewDebouncer[S, T](options.Duration)
+		case DebounceTrailing:
+			return NewTrailingDebouncer[S, T](options.Duration, options.MaxWait)
+		case DebounceReplay:
+			return NewReplayDebouncer[S, T](options.Duration)
+		}
+		panic("not supported")
+	}
+	var debouncers partitionSet[K, *Debouncer[S, T]] = internal.NewPartitions[K, *Debouncer[S, T]](
+		options.MaxKeys,
+		options.IdleTTL,
+		func(d *Debouncer[S, T]) bool { return d.idle() },
+		internal.DefaultTimeProvider)
+
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		debouncer, release := debouncers.Acquire(options.Key(ctx, s), newDebouncer)
+		defer release()
+		return debouncer.policy(ctx, f, s)
+	}
+}
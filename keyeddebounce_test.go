@@ -0,0 +1,117 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyedDebounce(t *testing.T) {
+	user := func(ctx context.Context, s string) string { return s }
+
+	t.Run("should debounce calls per key in leading mode", func(t *testing.T) {
+		// Arrange
+		policy := NewKeyedDebouncePolicy[string, int](KeyedDebounceOptions[string, string]{
+			Key:      user,
+			Duration: 1 * time.Hour,
+		})
+		f := func(ctx context.Context, s string) (int, error) { return len(s), nil }
+
+		// Act
+		_, errA1 := policy(context.Background(), f, "alice")
+		_, errA2 := policy(context.Background(), f, "alice")
+		vB, errB := policy(context.Background(), f, "bob")
+
+		// Assert
+		if errA1 != nil || !errors.Is(errA2, ErrDebounced) || vB != 3 || errB != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("should debounce calls per key in trailing mode", func(t *testing.T) {
+		// Arrange
+		policy := NewKeyedDebouncePolicy[string, int](KeyedDebounceOptions[string, string]{
+			Key:      user,
+			Duration: 30 * time.Millisecond,
+			Mode:     DebounceTrailing,
+		})
+		var calls atomic.Int32
+		f := func(ctx context.Context, s string) (int, error) {
+			calls.Add(1)
+			return len(s), nil
+		}
+		var wg sync.WaitGroup
+		results := make(chan int, 4)
+
+		// Act
+		for _, s := range []string{"alice", "bob", "alice", "bob"} {
+			wg.Add(1)
+			go func(s string) {
+				defer wg.Done()
+				v, _ := policy(context.Background(), f, s)
+				results <- v
+			}(s)
+		}
+		wg.Wait()
+		close(results)
+
+		// Assert
+		if calls.Load() != 2 {
+			t.Fail()
+		}
+		sum := 0
+		for v := range results {
+			sum += v
+		}
+		if sum != 5+5+3+3 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should replay outcome per key", func(t *testing.T) {
+		// Arrange
+		policy := NewKeyedDebouncePolicy[string, int](KeyedDebounceOptions[string, string]{
+			Key:      user,
+			Duration: 1 * time.Hour,
+			Mode:     DebounceReplay,
+		})
+		var calls int
+		f := func(ctx context.Context, s string) (int, error) {
+			calls++
+			return calls, nil
+		}
+
+		// Act
+		vA1, _ := policy(context.Background(), f, "alice")
+		vB, _ := policy(context.Background(), f, "bob")
+		vA2, _ := policy(context.Background(), f, "alice")
+
+		// Assert
+		if vA1 != 1 || vB != 2 || vA2 != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not forget key while its debounce window is open", func(t *testing.T) {
+		// Arrange
+		policy := NewKeyedDebouncePolicy[string, int](KeyedDebounceOptions[string, string]{
+			Key:      user,
+			Duration: 1 * time.Hour,
+			MaxKeys:  1,
+		})
+		f := func(ctx context.Context, s string) (int, error) { return len(s), nil }
+
+		// Act
+		policy(context.Background(), f, "alice")
+		policy(context.Background(), f, "bob")
+		_, err := policy(context.Background(), f, "alice")
+
+		// Assert
+		if !errors.Is(err, ErrDebounced) {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+var ErrPacingRejected = errors.New("pacing rejected")
+var ErrPacingQueueFull = fmt.Errorf("%w: queue is full", ErrPacingRejected)
+var ErrPacingDeadlineExceeded = fmt.Errorf("%w: scheduled slot is past deadline", ErrPacingRejected)
+
+func (pf PolicyFunc[S, T]) Pacing(interval time.Duration, queue int) PolicyFunc[S, T] {
+	return NewPacingPolicy[S, T](interval, queue).Bind(pf)
+}
+
+func NewPacingPolicy[S any, T any](interval time.Duration, queue int) Policy[S, T] {
+	pacer := internal.NewPacer(interval, queue, internal.DefaultTimeProvider)
+
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		var zero T
+		if ctx.Err() != nil {
+			return zero, ctx.Err()
+		}
+		deadline, _ := ctx.Deadline()
+		slot, delay, err := pacer.Reserve(deadline)
+		if errors.Is(err, internal.ErrPacerQueueFull) {
+			return zero, ErrPacingQueueFull
+		}
+		if errors.Is(err, internal.ErrPacerSlotPastDeadline) {
+			return zero, ErrPacingDeadlineExceeded
+		}
+		if delay == 0 {
+			return f(ctx, s)
+		}
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			pacer.Dequeue(slot, true)
+			return f(ctx, s)
+		case <-ctx.Done():
+			pacer.Dequeue(slot, false)
+			return zero, ctx.Err()
+		}
+	}
+}
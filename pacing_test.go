@@ -0,0 +1,99 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPacing(t *testing.T) {
+	t.Run("should release calls at evenly spaced intervals", func(t *testing.T) {
+		// Arrange
+		interval := 50 * time.Millisecond
+		policy := NewPacingPolicy[int, time.Time](interval, 10)
+		f := func(ctx context.Context, _ int) (time.Time, error) {
+			return time.Now(), nil
+		}
+		times := make([]time.Time, 3)
+		wg := sync.WaitGroup{}
+
+		// Act
+		start := time.Now()
+		for i := 0; i < len(times); i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				times[i], _ = policy(context.Background(), f, i)
+			}(i)
+		}
+		wg.Wait()
+
+		// Assert
+		latest := start
+		for _, tm := range times {
+			if tm.After(latest) {
+				latest = tm
+			}
+		}
+		if latest.Sub(start) < 2*interval {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject call when queue is full", func(t *testing.T) {
+		// Arrange
+		policy := NewPacingPolicy[string, int](1*time.Hour, 0)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		_, err1 := policy(context.Background(), f, "foo")
+		_, err2 := policy(context.Background(), f, "bar")
+
+		// Assert
+		if err1 != nil || !errors.Is(err2, ErrPacingQueueFull) || !errors.Is(err2, ErrPacingRejected) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject call when scheduled slot is past deadline", func(t *testing.T) {
+		// Arrange
+		policy := NewPacingPolicy[string, int](1*time.Hour, 10)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+		defer cancel()
+
+		// Act
+		policy(ctx, f, "foo")
+		_, err := policy(ctx, f, "bar")
+
+		// Assert
+		if !errors.Is(err, ErrPacingDeadlineExceeded) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should be able to cancel waiting", func(t *testing.T) {
+		// Arrange
+		policy := NewPacingPolicy[string, int](1*time.Hour, 10)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// Act
+		policy(ctx, f, "foo")
+		time.AfterFunc(100*time.Millisecond, cancel)
+		_, err := policy(ctx, f, "bar")
+
+		// Assert
+		if !errors.Is(err, context.Canceled) {
+			t.Fail()
+		}
+	})
+}
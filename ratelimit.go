@@ -3,20 +3,75 @@ package resilience
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/mapogolions/resilience/internal"
 )
 
 var ErrRateLimitRejected = errors.New("rate limit rejected")
+var ErrRateLimitCostExceedsCapacity = fmt.Errorf("%w: cost exceeds capacity", ErrRateLimitRejected)
 
 type RateLimit func() (bool, time.Duration)
 
+type RateLimitError struct {
+	Key        any
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Key == nil {
+		return fmt.Sprintf("%s: retry after %s", ErrRateLimitRejected, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s: key %v, retry after %s", ErrRateLimitRejected, e.Key, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimitRejected
+}
+
 func LockFreeTokenBucketRateLimit(tokenPerUnit time.Duration, capacity int64) RateLimit {
 	rateLimiter := internal.NewLockFreeTokenBucketRateLimiter(tokenPerUnit, capacity, internal.DefaultTimeProvider)
 	return rateLimiter.Try
 }
 
+type RateLimiter[S any, T any] struct {
+	rateLimit       RateLimit
+	availableTokens func() int64
+	counters        policyCounters
+	gate            policyGate
+}
+
+func NewTokenBucketRateLimiter[S any, T any](tokenPerUnit time.Duration, capacity int64) *RateLimiter[S, T] {
+	rateLimiter := internal.NewLockFreeTokenBucketRateLimiter(tokenPerUnit, capacity, internal.DefaultTimeProvider)
+	return &RateLimiter[S, T]{rateLimit: rateLimiter.Try, availableTokens: rateLimiter.FreeTokens}
+}
+
+func (rl *RateLimiter[S, T]) Policy() Policy[S, T] {
+	return gated(&rl.gate, &rl.counters, func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		var zero T
+		if ok, _ := rl.rateLimit(); !ok {
+			rl.counters.reject()
+			return zero, ErrRateLimitRejected
+		}
+		return countedCall(&rl.counters, ctx, f, s)
+	})
+}
+
+func (rl *RateLimiter[S, T]) Drain(ctx context.Context) error {
+	return rl.gate.drain(ctx)
+}
+
+func (rl *RateLimiter[S, T]) Close() {
+	rl.gate.close()
+}
+
+func (rl *RateLimiter[S, T]) Stats() Stats {
+	stats := rl.counters.snapshot()
+	stats.AvailableTokens = rl.availableTokens()
+	return stats
+}
+
 func (pf PolicyFunc[S, T]) RateLimit(rateLimit RateLimit) PolicyFunc[S, T] {
 	return NewRateLimitPolicy[S, T](rateLimit).Bind(pf)
 }
@@ -31,3 +86,53 @@ func NewRateLimitPolicy[S any, T any](rateLimit RateLimit) Policy[S, T] {
 		return f(ctx, s)
 	}
 }
+
+func NewKeyedRateLimitPolicy[S any, T any, K comparable](
+	key KeyFunc[S, K],
+	factory func(K) RateLimit,
+	maxKeys int,
+	idleTTL time.Duration) Policy[S, T] {
+
+	var zero T
+	rateLimits := internal.NewLruCache[K, RateLimit](maxKeys, idleTTL, nil, internal.DefaultTimeProvider)
+
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		k := key(ctx, s)
+		rateLimit := rateLimits.GetOrAdd(k, factory)
+		if ok, retryAfter := rateLimit(); !ok {
+			return zero, &RateLimitError{Key: k, RetryAfter: retryAfter}
+		}
+		return f(ctx, s)
+	}
+}
+
+func (pf PolicyFunc[S, T]) WeightedRateLimit(
+	tokenPerUnit time.Duration,
+	capacity int64,
+	cost func(S) int64) PolicyFunc[S, T] {
+
+	return NewWeightedRateLimitPolicy[S, T](tokenPerUnit, capacity, cost).Bind(pf)
+}
+
+// Calls with non-positive cost are not limited
+func NewWeightedRateLimitPolicy[S any, T any](
+	tokenPerUnit time.Duration,
+	capacity int64,
+	cost func(S) int64) Policy[S, T] {
+
+	var zero T
+	rateLimiter := internal.NewTokenBucketRateLimiter(tokenPerUnit, capacity, internal.DefaultTimeProvider)
+
+	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		n := cost(s)
+		if n > capacity {
+			return zero, ErrRateLimitCostExceedsCapacity
+		}
+		if n > 0 {
+			if ok, retryAfter := rateLimiter.TryN(n); !ok {
+				return zero, &RateLimitError{RetryAfter: retryAfter}
+			}
+		}
+		return f(ctx, s)
+	}
+}
@@ -46,3 +46,97 @@ func TestRateLimit(t *testing.T) {
 		}
 	})
 }
+
+func TestKeyedRateLimit(t *testing.T) {
+	t.Run("should limit each key independently", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		key := func(_ context.Context, s string) string { return s }
+		factory := func(string) RateLimit { return LockFreeTokenBucketRateLimit(1*time.Hour, 1) }
+		policy := NewKeyedRateLimitPolicy[string, int](key, factory, 0, 0)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		_, err1 := policy(ctx, f, "foo")
+		_, err2 := policy(ctx, f, "bar")
+		_, err3 := policy(ctx, f, "foo")
+
+		// Assert
+		if err1 != nil || err2 != nil {
+			t.Fail()
+		}
+		var rateLimitErr *RateLimitError
+		if !errors.Is(err3, ErrRateLimitRejected) || !errors.As(err3, &rateLimitErr) {
+			t.FailNow()
+		}
+		if rateLimitErr.Key != "foo" || rateLimitErr.RetryAfter <= 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should create new rate limit for evicted key", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		key := func(_ context.Context, s string) string { return s }
+		factory := func(string) RateLimit { return LockFreeTokenBucketRateLimit(1*time.Hour, 1) }
+		policy := NewKeyedRateLimitPolicy[string, int](key, factory, 1, 0)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		policy(ctx, f, "foo")
+		policy(ctx, f, "bar") // evicts "foo"
+		_, err := policy(ctx, f, "foo")
+
+		// Assert
+		if err != nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestWeightedRateLimit(t *testing.T) {
+	t.Run("should take as many tokens as call costs", func(t *testing.T) {
+		// Arrange
+		ctx := context.Background()
+		cost := func(s string) int64 { return int64(len(s)) }
+		policy := NewWeightedRateLimitPolicy[string, int](1*time.Hour, 5, cost)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		_, err1 := policy(ctx, f, "foo")
+		_, err2 := policy(ctx, f, "bar")
+		_, err3 := policy(ctx, f, "ba")
+
+		// Assert
+		if err1 != nil || err3 != nil {
+			t.Fail()
+		}
+		var rateLimitErr *RateLimitError
+		if !errors.As(err2, &rateLimitErr) || rateLimitErr.RetryAfter <= 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject call which costs more than capacity", func(t *testing.T) {
+		// Arrange
+		cost := func(s string) int64 { return int64(len(s)) }
+		policy := NewWeightedRateLimitPolicy[string, int](1*time.Second, 2, cost)
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		_, err := policy(context.Background(), f, "foo")
+
+		// Assert
+		if !errors.Is(err, ErrRateLimitCostExceedsCapacity) || !errors.Is(err, ErrRateLimitRejected) {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package resilience
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Stats is a point-in-time snapshot of a stateful policy.
+// Fields that do not make sense for a particular policy are left zero.
+type Stats struct {
+	InFlight        int64
+	Queued          int64
+	AvailableTokens int64
+	Accepted        int64
+	Rejected        int64
+	Failed          int64
+	CircuitState    CircuitState
+}
+
+type policyCounters struct {
+	inFlight atomic.Int64
+	accepted atomic.Int64
+	rejected atomic.Int64
+	failed   atomic.Int64
+}
+
+func (c *policyCounters) snapshot() Stats {
+	return Stats{
+		InFlight: c.inFlight.Load(),
+		Accepted: c.accepted.Load(),
+		Rejected: c.rejected.Load(),
+		Failed:   c.failed.Load(),
+	}
+}
+
+func (c *policyCounters) reject() {
+	c.rejected.Add(1)
+}
+
+func countedCall[S any, T any](
+	c *policyCounters,
+	ctx context.Context,
+	f func(context.Context, S) (T, error),
+	s S) (result T, err error) {
+
+	c.accepted.Add(1)
+	c.inFlight.Add(1)
+	defer func() {
+		c.inFlight.Add(-1)
+		if err != nil {
+			c.failed.Add(1)
+		}
+	}()
+	return f(ctx, s)
+}
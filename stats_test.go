@@ -0,0 +1,115 @@
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("bulkhead should report in-flight and queued calls", func(t *testing.T) {
+		// Arrange
+		bulkhead := NewBulkhead[string, int](1, 1)
+		policy := bulkhead.Policy()
+		started := make(chan struct{})
+		barrier := make(chan struct{})
+		done := make(chan struct{}, 2)
+		f := func(ctx context.Context, s string) (int, error) {
+			started <- struct{}{}
+			<-barrier
+			return len(s), nil
+		}
+		for i := 0; i < 2; i++ {
+			go func() {
+				policy(context.Background(), f, "foo")
+				done <- struct{}{}
+			}()
+		}
+		<-started
+		for bulkhead.Stats().Queued != 1 {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Act
+		policy(context.Background(), f, "bar") // rejected
+		stats := bulkhead.Stats()
+		close(barrier)
+		<-started
+		<-done
+		<-done
+
+		// Assert
+		if stats.InFlight != 1 || stats.Queued != 1 || stats.Accepted != 1 || stats.Rejected != 1 {
+			t.Fail()
+		}
+		if final := bulkhead.Stats(); final.InFlight != 0 || final.Accepted != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("rate limiter should report available tokens", func(t *testing.T) {
+		// Arrange
+		rateLimiter := NewTokenBucketRateLimiter[string, int](1*time.Hour, 2)
+		policy := rateLimiter.Policy()
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		policy(context.Background(), f, "foo")
+		stats1 := rateLimiter.Stats()
+		policy(context.Background(), f, "foo")
+		policy(context.Background(), f, "foo")
+		stats2 := rateLimiter.Stats()
+
+		// Assert
+		if stats1.AvailableTokens != 1 || stats1.Accepted != 1 {
+			t.Fail()
+		}
+		if stats2.AvailableTokens != 0 || stats2.Accepted != 2 || stats2.Rejected != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("circuit should report state and failures", func(t *testing.T) {
+		// Arrange
+		circuit := NewConsecutiveFailuresCircuit[string, int](1, 1*time.Hour, RejectOnError)
+		policy := circuit.Policy()
+		f := func(ctx context.Context, s string) (int, error) {
+			return 0, errSomethingWentWrong
+		}
+
+		// Act
+		before := circuit.Stats()
+		policy(context.Background(), f, "foo")
+		policy(context.Background(), f, "foo")
+		after := circuit.Stats()
+
+		// Assert
+		if before.CircuitState != CircuitClosed {
+			t.Fail()
+		}
+		if after.CircuitState != CircuitOpen || after.Failed != 1 || after.Rejected != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("debouncer should report debounced calls as rejected", func(t *testing.T) {
+		// Arrange
+		debouncer := NewDebouncer[string, int](1 * time.Hour)
+		policy := debouncer.Policy()
+		f := func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}
+
+		// Act
+		policy(context.Background(), f, "foo")
+		policy(context.Background(), f, "foo")
+		stats := debouncer.Stats()
+
+		// Assert
+		if stats.Accepted != 1 || stats.Rejected != 1 || stats.Failed != 0 {
+			t.Fail()
+		}
+	})
+}
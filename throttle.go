@@ -0,0 +1,161 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+var ErrThrottled = errors.New("call throttled")
+
+type ThrottleOptions struct {
+	Interval time.Duration
+	// Runs the call that opens an interval right away
+	Leading bool
+	// Defers the most recent call made within an interval to its end.
+	// The caller whose call is deferred waits for its outcome.
+	Trailing bool
+	// Throttled callers receive the outcome of the most recent execution instead
+	// of ErrThrottled, once there is one
+	ReturnLast bool
+	// Defaults to the system clock. Trailing executions are scheduled through
+	// the time provider when it implements Scheduler, and with system timers otherwise.
+	TimeProvider TimeProvider
+}
+
+type throttledCall[S any, T any] struct {
+	ctx    context.Context
+	f      func(context.Context, S) (T, error)
+	s      S
+	done   chan struct{}
+	result T
+	err    error
+}
+
+type Throttler[S any, T any] struct {
+	m         sync.Mutex
+	options   ThrottleOptions
+	windowEnd time.Time
+	pending   *throttledCall[S, T]
+	scheduled bool
+	last      *result[T]
+	counters  policyCounters
+	gate      policyGate
+}
+
+func (pf PolicyFunc[S, T]) Throttle(options ThrottleOptions) PolicyFunc[S, T] {
+	return NewThrottlePolicy[S, T](options).Bind(pf)
+}
+
+func NewThrottlePolicy[S any, T any](options ThrottleOptions) Policy[S, T] {
+	return NewThrottler[S, T](options).Policy()
+}
+
+// NewThrottler runs at most one call per interval no matter how many calls arrive
+func NewThrottler[S any, T any](options ThrottleOptions) *Throttler[S, T] {
+	if options.Interval <= 0 {
+		panic("interval must be > 0")
+	}
+	if !options.Leading && !options.Trailing {
+		panic("either leading or trailing execution must be enabled")
+	}
+	if options.TimeProvider == nil {
+		options.TimeProvider = internal.DefaultTimeProvider
+	}
+	return &Throttler[S, T]{options: options}
+}
+
+func (t *Throttler[S, T]) Policy() Policy[S, T] {
+	return gated(&t.gate, &t.counters, func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+		if err := ctx.Err(); err != nil {
+			var zero T
+			return zero, err
+		}
+		t.m.Lock()
+		now := t.options.TimeProvider.UtcNow()
+		if !now.Before(t.windowEnd) && t.pending == nil {
+			t.windowEnd = now.Add(t.options.Interval)
+			if t.options.Leading {
+				t.m.Unlock()
+				return t.run(ctx, f, s)
+			}
+		}
+		if !t.options.Trailing {
+			defer t.m.Unlock()
+			return t.throttled()
+		}
+		call := &throttledCall[S, T]{ctx: ctx, f: f, s: s, done: make(chan struct{})}
+		if superseded := t.pending; superseded != nil {
+			superseded.result, superseded.err = t.throttled()
+			close(superseded.done)
+		}
+		t.pending = call
+		if !t.scheduled {
+			t.scheduled = true
+			internal.AfterFunc(t.options.TimeProvider, t.windowEnd.Sub(now), t.runTrailing)
+		}
+		t.m.Unlock()
+
+		select {
+		case <-call.done:
+			return call.result, call.err
+		case <-ctx.Done():
+			t.m.Lock()
+			defer t.m.Unlock()
+			if t.pending == call {
+				t.pending = nil
+			}
+			var zero T
+			return zero, ctx.Err()
+		}
+	})
+}
+
+func (t *Throttler[S, T]) runTrailing() {
+	t.m.Lock()
+	call := t.pending
+	t.pending = nil
+	t.scheduled = false
+	if call == nil {
+		t.m.Unlock()
+		return
+	}
+	t.windowEnd = t.options.TimeProvider.UtcNow().Add(t.options.Interval)
+	t.m.Unlock()
+
+	call.result, call.err = t.run(call.ctx, call.f, call.s)
+	close(call.done)
+}
+
+func (t *Throttler[S, T]) run(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
+	value, err := countedCall(&t.counters, ctx, f, s)
+	t.m.Lock()
+	defer t.m.Unlock()
+	t.last = &result[T]{value, err}
+	return value, err
+}
+
+// must be called under the lock
+func (t *Throttler[S, T]) throttled() (T, error) {
+	if t.options.ReturnLast && t.last != nil {
+		return t.last.Value, t.last.Err
+	}
+	t.counters.reject()
+	var zero T
+	return zero, ErrThrottled
+}
+
+func (t *Throttler[S, T]) Drain(ctx context.Context) error {
+	return t.gate.drain(ctx)
+}
+
+func (t *Throttler[S, T]) Close() {
+	t.gate.close()
+}
+
+func (t *Throttler[S, T]) Stats() Stats {
+	return t.counters.snapshot()
+}
@@ -0,0 +1,186 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mapogolions/resilience/internal"
+)
+
+func TestThrottle(t *testing.T) {
+	t.Run("should run at most once per interval when calls keep coming", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		policy := NewThrottlePolicy[int, int](ThrottleOptions{
+			Interval:     100 * time.Millisecond,
+			Leading:      true,
+			TimeProvider: timeProvider,
+		})
+		var calls int
+		f := func(ctx context.Context, n int) (int, error) {
+			calls++
+			return n, nil
+		}
+		var throttled int
+
+		// Act
+		for i := 0; i < 30; i++ {
+			if _, err := policy(context.Background(), f, i); errors.Is(err, ErrThrottled) {
+				throttled++
+			}
+			timeProvider.Advance(10 * time.Millisecond)
+		}
+
+		// Assert
+		if calls != 3 || throttled != 27 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should return outcome of the most recent execution to throttled callers", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		policy := NewThrottlePolicy[int, int](ThrottleOptions{
+			Interval:     100 * time.Millisecond,
+			Leading:      true,
+			ReturnLast:   true,
+			TimeProvider: timeProvider,
+		})
+		f := newSliceIndexer([]int{10, 20, 30})
+
+		// Act
+		v1, _ := policy(context.Background(), f, 0)
+		v2, err := policy(context.Background(), f, 1)
+		timeProvider.Advance(100 * time.Millisecond)
+		v3, _ := policy(context.Background(), f, 2)
+
+		// Assert
+		if v1 != 10 || v2 != 10 || err != nil || v3 != 30 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not count replayed outcome as rejection", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		throttler := NewThrottler[int, int](ThrottleOptions{
+			Interval:     100 * time.Millisecond,
+			Leading:      true,
+			ReturnLast:   true,
+			TimeProvider: timeProvider,
+		})
+		policy := throttler.Policy()
+		f := newSliceIndexer([]int{10, 20})
+
+		// Act
+		policy(context.Background(), f, 0)
+		v, err := policy(context.Background(), f, 1)
+
+		// Assert
+		stats := throttler.Stats()
+		if v != 10 || err != nil || stats.Rejected != 0 || stats.Accepted != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should defer the most recent call within interval to its end", func(t *testing.T) {
+		// Arrange
+		policy := NewThrottlePolicy[int, int](ThrottleOptions{
+			Interval: 50 * time.Millisecond,
+			Leading:  true,
+			Trailing: true,
+		})
+		var calls atomic.Int32
+		f := func(ctx context.Context, n int) (int, error) {
+			calls.Add(1)
+			return n, nil
+		}
+		superseded := make(chan error)
+
+		// Act
+		v1, _ := policy(context.Background(), f, 1)
+		go func() {
+			_, err := policy(context.Background(), f, 2)
+			superseded <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		start := time.Now()
+		v3, _ := policy(context.Background(), f, 3)
+		elapsed := time.Since(start)
+
+		// Assert
+		if v1 != 1 || v3 != 3 || !errors.Is(<-superseded, ErrThrottled) || calls.Load() != 2 {
+			t.Fail()
+		}
+		if elapsed < 30*time.Millisecond {
+			t.Fail()
+		}
+	})
+
+	t.Run("should run only trailing call when leading execution is disabled", func(t *testing.T) {
+		// Arrange
+		throttler := NewThrottler[int, int](ThrottleOptions{
+			Interval: 20 * time.Millisecond,
+			Trailing: true,
+		})
+		var calls atomic.Int32
+		f := func(ctx context.Context, n int) (int, error) {
+			calls.Add(1)
+			return n, nil
+		}
+
+		// Act
+		v, err := throttler.Policy()(context.Background(), f, 1)
+
+		// Assert
+		if v != 1 || err != nil || calls.Load() != 1 || throttler.Stats().Accepted != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should schedule trailing call with injected clock", func(t *testing.T) {
+		// Arrange
+		timeProvider := internal.NewFakeTimeProvider()
+		throttler := NewThrottler[int, int](ThrottleOptions{
+			Interval:     100 * time.Millisecond,
+			Leading:      true,
+			Trailing:     true,
+			TimeProvider: timeProvider,
+		})
+		policy := throttler.Policy()
+		f := func(ctx context.Context, n int) (int, error) { return n, nil }
+		policy(context.Background(), f, 1)
+		trailing := make(chan int)
+		go func() {
+			v, _ := policy(context.Background(), f, 2)
+			trailing <- v
+		}()
+		for !hasPending(throttler) {
+			time.Sleep(time.Millisecond)
+		}
+
+		// Act
+		timeProvider.Advance(50 * time.Millisecond)
+		var firedEarly bool
+		select {
+		case <-trailing:
+			firedEarly = true
+		case <-time.After(20 * time.Millisecond):
+		}
+		timeProvider.Advance(50 * time.Millisecond)
+
+		// Assert
+		if firedEarly || <-trailing != 2 {
+			t.Fail()
+		}
+	})
+}
+
+func hasPending[S any, T any](t *Throttler[S, T]) bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+	return t.pending != nil
+}
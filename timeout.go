@@ -3,10 +3,60 @@ package resilience
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 )
 
 var ErrTimeoutRejected = errors.New("rejected by timeout")
+var ErrAbandonedLimitExceeded = errors.New("too many abandoned calls")
+
+// TimeoutError matches both ErrTimeoutRejected and context.DeadlineExceeded
+type TimeoutError struct {
+	Kind    TimeoutPolicyKind
+	Timeout time.Duration
+	Elapsed time.Duration
+	// The deadline of the caller's context was earlier than the timeout
+	ParentDeadline bool
+}
+
+func (e *TimeoutError) Error() string {
+	cause := "timeout"
+	if e.ParentDeadline {
+		cause = "parent deadline"
+	}
+	return fmt.Sprintf("%s: %s %s timeout %s, elapsed %s", ErrTimeoutRejected, cause, e.Kind, e.Timeout, e.Elapsed)
+}
+
+func (e *TimeoutError) Unwrap() []error {
+	return []error{ErrTimeoutRejected, context.DeadlineExceeded}
+}
+
+func newTimeoutError(
+	ctx context.Context,
+	kind TimeoutPolicyKind,
+	timeout time.Duration,
+	start time.Time,
+	deadline time.Time) *TimeoutError {
+
+	return &TimeoutError{
+		Kind:           kind,
+		Timeout:        timeout,
+		Elapsed:        time.Since(start),
+		ParentDeadline: isInheritParentTimeout(deadline, ctx),
+	}
+}
+
+// TimeoutFunc picks the timeout of a call. Zero or a negative value means no timeout.
+type TimeoutFunc[S any] func(context.Context, S) time.Duration
+
+// FixedTimeout gives every call the same timeout. Being a TimeoutFunc, zero
+// means no timeout, unlike the timeout of NewTimeoutPolicy.
+func FixedTimeout[S any](timeout time.Duration) TimeoutFunc[S] {
+	return func(context.Context, S) time.Duration {
+		return timeout
+	}
+}
 
 type result[T any] struct {
 	Value T
@@ -20,68 +70,197 @@ const (
 	PessimisticTimeoutPolicy TimeoutPolicyKind = 1
 )
 
+func (k TimeoutPolicyKind) String() string {
+	switch k {
+	case OptimisticTimeoutPolicy:
+		return "optimistic"
+	case PessimisticTimeoutPolicy:
+		return "pessimistic"
+	}
+	return fmt.Sprintf("TimeoutPolicyKind(%d)", int(k))
+}
+
+// NewTimeoutPolicy gives every call the same timeout. A call with zero or a
+// negative timeout receives an already expired context.
 func NewTimeoutPolicy[S any, T any](timeout time.Duration, kind TimeoutPolicyKind) Policy[S, T] {
 	if kind == OptimisticTimeoutPolicy {
-		return optimisticTimeout[S, T](timeout)
+		return optimisticTimeout[S, T](FixedTimeout[S](timeout), false)
+	}
+	if kind == PessimisticTimeoutPolicy {
+		return NewPessimisticTimeout[S, T](PessimisticTimeoutOptions[S, T]{Timeout: timeout}).Policy()
+	}
+	panic("not supported")
+}
+
+// NewDynamicTimeoutPolicy picks the timeout per call. Zero or a negative timeout means no timeout.
+func NewDynamicTimeoutPolicy[S any, T any](timeout TimeoutFunc[S], kind TimeoutPolicyKind) Policy[S, T] {
+	if kind == OptimisticTimeoutPolicy {
+		return optimisticTimeout[S, T](timeout, true)
 	}
 	if kind == PessimisticTimeoutPolicy {
-		return pessimisticTimeout[S, T](timeout)
+		return NewPessimisticTimeout[S, T](PessimisticTimeoutOptions[S, T]{TimeoutFunc: timeout}).Policy()
 	}
 	panic("not supported")
 }
 
-func pessimisticTimeout[S any, T any](timeout time.Duration) Policy[S, T] {
+type PessimisticTimeoutOptions[S any, T any] struct {
+	// Same for every call, zero or a negative value expires calls immediately
+	// as with NewTimeoutPolicy
+	Timeout time.Duration
+	// Picks the timeout per call, takes precedence over Timeout.
+	// Zero or a negative value means no timeout.
+	TimeoutFunc TimeoutFunc[S]
+	// Receives the late outcome of an abandoned call and how long after the
+	// timeout it completed
+	OnAbandoned func(result T, err error, overrun time.Duration)
+	// New calls are rejected while this many abandoned calls are still running.
+	// Zero means no limit.
+	MaxAbandoned int
+	// Time `f` is given to return after its context has been cancelled by the
+	// timeout, for instance to roll back a transaction. If it returns within the
+	// grace period, its outcome is delivered. Otherwise the call is abandoned.
+	GracePeriod time.Duration
+}
+
+type PessimisticTimeout[S any, T any] struct {
+	options   PessimisticTimeoutOptions[S, T]
+	timeout   TimeoutFunc[S]
+	dynamic   bool
+	abandoned atomic.Int64
+}
+
+const (
+	callRunning   int32 = 0
+	callCompleted int32 = 1
+	callAbandoned int32 = 2
+)
+
+func NewPessimisticTimeout[S any, T any](options PessimisticTimeoutOptions[S, T]) *PessimisticTimeout[S, T] {
+	if options.MaxAbandoned < 0 {
+		panic("max abandoned must be >= 0")
+	}
+	if options.GracePeriod < 0 {
+		panic("grace period must be >= 0")
+	}
+	timeout := options.TimeoutFunc
+	if timeout == nil {
+		timeout = FixedTimeout[S](options.Timeout)
+	}
+	return &PessimisticTimeout[S, T]{options: options, timeout: timeout, dynamic: options.TimeoutFunc != nil}
+}
+
+// Abandoned reports the number of calls that have been abandoned by timeout and are still running
+func (pt *PessimisticTimeout[S, T]) Abandoned() int64 {
+	return pt.abandoned.Load()
+}
+
+func (pt *PessimisticTimeout[S, T]) Policy() Policy[S, T] {
 	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
 		var zero T
 		if ctx.Err() != nil {
 			return zero, ctx.Err()
 		}
-		deadline := time.Now().Add(timeout)
+		timeout := pt.timeout(ctx, s)
+		if pt.dynamic && timeout <= 0 {
+			return f(ctx, s)
+		}
+		if pt.options.MaxAbandoned > 0 && pt.abandoned.Load() >= int64(pt.options.MaxAbandoned) {
+			return zero, ErrAbandonedLimitExceeded
+		}
+		start := time.Now()
+		deadline := start.Add(timeout)
 		timeoutCtx, timeoutCancel := context.WithDeadline(ctx, deadline)
 		defer timeoutCancel()
 
+		// Whoever moves the call out of the running state first decides its fate
+		var state atomic.Int32
+		var timedOutAt time.Time
 		dataCh := func() <-chan result[T] {
 			ch := make(chan result[T], 1)
 			go func() {
 				defer close(ch)
 				v, err := f(timeoutCtx, s)
-				ch <- result[T]{v, err}
+				if state.CompareAndSwap(callRunning, callCompleted) {
+					ch <- result[T]{v, err}
+					return
+				}
+				pt.abandoned.Add(-1)
+				if pt.options.OnAbandoned != nil {
+					pt.options.OnAbandoned(v, err, time.Since(timedOutAt))
+				}
 			}()
 			return ch
 		}()
 
 		select {
 		case <-timeoutCtx.Done():
-			return zero, ErrTimeoutRejected
-		case result := <-dataCh:
-			if result.Err == nil {
-				return result.Value, nil
+			// The overrun includes the grace period
+			timedOutAt = time.Now()
+			if result, ok := pt.awaitGracePeriod(dataCh); ok {
+				return timeoutOutcome(timeoutCtx, ctx, PessimisticTimeoutPolicy, timeout, start, deadline, result)
 			}
-			if errors.Is(result.Err, context.DeadlineExceeded) && !isInheritParentTimeout(deadline, ctx) {
-				return zero, ErrTimeoutRejected
+			if state.CompareAndSwap(callRunning, callAbandoned) {
+				pt.abandoned.Add(1)
+				return zero, newTimeoutError(ctx, PessimisticTimeoutPolicy, timeout, start, deadline)
 			}
-			return zero, result.Err
+			return timeoutOutcome(timeoutCtx, ctx, PessimisticTimeoutPolicy, timeout, start, deadline, <-dataCh)
+		case result := <-dataCh:
+			return timeoutOutcome(timeoutCtx, ctx, PessimisticTimeoutPolicy, timeout, start, deadline, result)
 		}
 	}
 }
 
-func optimisticTimeout[S any, T any](timeout time.Duration) Policy[S, T] {
+func (pt *PessimisticTimeout[S, T]) awaitGracePeriod(dataCh <-chan result[T]) (result[T], bool) {
+	if pt.options.GracePeriod == 0 {
+		return result[T]{}, false
+	}
+	timer := time.NewTimer(pt.options.GracePeriod)
+	defer timer.Stop()
+	select {
+	case result := <-dataCh:
+		return result, true
+	case <-timer.C:
+		return result[T]{}, false
+	}
+}
+
+func timeoutOutcome[T any](
+	timeoutCtx context.Context,
+	ctx context.Context,
+	kind TimeoutPolicyKind,
+	timeout time.Duration,
+	start time.Time,
+	deadline time.Time,
+	result result[T]) (T, error) {
+
+	var zero T
+	if result.Err == nil {
+		return result.Value, nil
+	}
+	// A deadline exceeded error of `f` unrelated to the timeout context is passed through as is
+	if errors.Is(result.Err, context.DeadlineExceeded) && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return zero, newTimeoutError(ctx, kind, timeout, start, deadline)
+	}
+	return zero, result.Err
+}
+
+// A dynamic timeout of zero or less means no timeout
+func optimisticTimeout[S any, T any](timeoutFunc TimeoutFunc[S], dynamic bool) Policy[S, T] {
 	return func(ctx context.Context, f func(context.Context, S) (T, error), s S) (T, error) {
 		var zero T
 		if ctx.Err() != nil {
 			return zero, ctx.Err()
 		}
-		deadline := time.Now().Add(timeout)
+		timeout := timeoutFunc(ctx, s)
+		if dynamic && timeout <= 0 {
+			return f(ctx, s)
+		}
+		start := time.Now()
+		deadline := start.Add(timeout)
 		timeoutCtx, timeoutCancel := context.WithDeadline(ctx, deadline)
 		defer timeoutCancel()
 		value, err := f(timeoutCtx, s)
-		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) && !isInheritParentTimeout(deadline, ctx) {
-				return zero, ErrTimeoutRejected
-			}
-			return zero, err
-		}
-		return value, nil
+		return timeoutOutcome(timeoutCtx, ctx, OptimisticTimeoutPolicy, timeout, start, deadline, result[T]{value, err})
 	}
 }
 
@@ -54,6 +54,106 @@ func TestPessimisticTimeout(t *testing.T) {
 	})
 }
 
+func TestPessimisticTimeoutAbandonedWork(t *testing.T) {
+	t.Run("should report late result of abandoned call", func(t *testing.T) {
+		// Arrange
+		type lateOutcome struct {
+			result  int
+			err     error
+			overrun time.Duration
+		}
+		outcomes := make(chan lateOutcome, 1)
+		timeout := NewPessimisticTimeout[string, int](PessimisticTimeoutOptions[string, int]{
+			Timeout: 10 * time.Millisecond,
+			OnAbandoned: func(result int, err error, overrun time.Duration) {
+				outcomes <- lateOutcome{result, err, overrun}
+			},
+		})
+		release := make(chan struct{})
+
+		// Act
+		_, err := timeout.Policy()(
+			context.Background(),
+			func(ctx context.Context, s string) (int, error) {
+				<-release
+				return len(s), errSomethingWentWrong
+			},
+			"foo",
+		)
+		abandoned := timeout.Abandoned()
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		outcome := <-outcomes
+
+		// Assert
+		if !errors.Is(err, ErrTimeoutRejected) || abandoned != 1 {
+			t.Fail()
+		}
+		if outcome.result != 3 || !errors.Is(outcome.err, errSomethingWentWrong) || outcome.overrun < 20*time.Millisecond {
+			t.Fail()
+		}
+		if timeout.Abandoned() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should not report calls completed in time", func(t *testing.T) {
+		// Arrange
+		var reported bool
+		timeout := NewPessimisticTimeout[string, int](PessimisticTimeoutOptions[string, int]{
+			Timeout:     1 * time.Hour,
+			OnAbandoned: func(int, error, time.Duration) { reported = true },
+		})
+
+		// Act
+		v, err := timeout.Policy()(
+			context.Background(),
+			func(ctx context.Context, s string) (int, error) { return len(s), nil },
+			"foo",
+		)
+
+		// Assert
+		if v != 3 || err != nil || reported || timeout.Abandoned() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject new calls while too many calls are abandoned", func(t *testing.T) {
+		// Arrange
+		done := make(chan struct{})
+		timeout := NewPessimisticTimeout[string, int](PessimisticTimeoutOptions[string, int]{
+			Timeout:      10 * time.Millisecond,
+			MaxAbandoned: 1,
+			OnAbandoned:  func(int, error, time.Duration) { close(done) },
+		})
+		policy := timeout.Policy()
+		release := make(chan struct{})
+		var calls int
+		f := func(ctx context.Context, s string) (int, error) {
+			calls++
+			<-release
+			return len(s), nil
+		}
+
+		// Act
+		_, err1 := policy(context.Background(), f, "foo")
+		_, err2 := policy(context.Background(), f, "foo")
+		close(release)
+		<-done
+		_, err3 := policy(context.Background(), func(ctx context.Context, s string) (int, error) {
+			return len(s), nil
+		}, "foo")
+
+		// Assert
+		if !errors.Is(err1, ErrTimeoutRejected) || !errors.Is(err2, ErrAbandonedLimitExceeded) || err3 != nil {
+			t.Fail()
+		}
+		if calls != 1 {
+			t.Fail()
+		}
+	})
+}
+
 func TestOptimisticTimeout(t *testing.T) {
 	t.Run("should return deadline exceeded error when context inherits deadline from parent context", func(t *testing.T) {
 		// Arrange
@@ -153,3 +253,259 @@ func TestOptimisticTimeout(t *testing.T) {
 		}
 	})
 }
+
+func TestDynamicTimeout(t *testing.T) {
+	timeoutFunc := func(ctx context.Context, route string) time.Duration {
+		if route == "slow" {
+			return 0
+		}
+		return 10 * time.Millisecond
+	}
+	kinds := []TimeoutPolicyKind{OptimisticTimeoutPolicy, PessimisticTimeoutPolicy}
+
+	t.Run("should expire call immediately when fixed timeout is zero", func(t *testing.T) {
+		for _, kind := range kinds {
+			// Arrange
+			policy := NewTimeoutPolicy[string, int](0, kind)
+
+			// Act
+			_, err := policy(
+				context.Background(),
+				func(ctx context.Context, s string) (int, error) {
+					<-ctx.Done()
+					return 0, ctx.Err()
+				},
+				"foo",
+			)
+
+			// Assert
+			if !errors.Is(err, ErrTimeoutRejected) {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("should reject call with timeout picked for the input", func(t *testing.T) {
+		for _, kind := range kinds {
+			// Arrange
+			policy := NewDynamicTimeoutPolicy[string, int](timeoutFunc, kind)
+
+			// Act
+			_, err := policy(
+				context.Background(),
+				func(ctx context.Context, s string) (int, error) {
+					<-ctx.Done()
+					return 0, ctx.Err()
+				},
+				"fast",
+			)
+
+			// Assert
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 10*time.Millisecond {
+				t.Fail()
+			}
+			if !errors.Is(err, ErrTimeoutRejected) {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("should not limit call when picked timeout is zero", func(t *testing.T) {
+		for _, kind := range kinds {
+			// Arrange
+			policy := NewDynamicTimeoutPolicy[string, int](timeoutFunc, kind)
+
+			// Act
+			v, err := policy(
+				context.Background(),
+				func(ctx context.Context, s string) (int, error) {
+					if _, ok := ctx.Deadline(); ok {
+						return 0, errSomethingWentWrong
+					}
+					time.Sleep(20 * time.Millisecond)
+					return len(s), nil
+				},
+				"slow",
+			)
+
+			// Assert
+			if v != 4 || err != nil {
+				t.Fail()
+			}
+		}
+	})
+}
+
+func TestTimeoutError(t *testing.T) {
+	blocked := func(ctx context.Context, s string) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+
+	t.Run("should match both rejected by timeout and deadline exceeded errors", func(t *testing.T) {
+		for _, kind := range []TimeoutPolicyKind{OptimisticTimeoutPolicy, PessimisticTimeoutPolicy} {
+			// Arrange
+			policy := NewTimeoutPolicy[string, int](10*time.Millisecond, kind)
+
+			// Act
+			_, err := policy(context.Background(), blocked, "foo")
+
+			// Assert
+			if !errors.Is(err, ErrTimeoutRejected) || !errors.Is(err, context.DeadlineExceeded) {
+				t.Fail()
+			}
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.FailNow()
+			}
+			if timeoutErr.Kind != kind || timeoutErr.Timeout != 10*time.Millisecond ||
+				timeoutErr.Elapsed < 10*time.Millisecond || timeoutErr.ParentDeadline {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("should report that deadline of parent context was the cause", func(t *testing.T) {
+		// Arrange
+		policy := NewTimeoutPolicy[string, int](1*time.Hour, OptimisticTimeoutPolicy)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// Act
+		_, err := policy(ctx, blocked, "foo")
+
+		// Assert
+		var timeoutErr *TimeoutError
+		if !errors.As(err, &timeoutErr) || !timeoutErr.ParentDeadline {
+			t.Fail()
+		}
+	})
+
+	t.Run("should pass through deadline exceeded error unrelated to timeout", func(t *testing.T) {
+		// Arrange
+		policy := NewTimeoutPolicy[string, int](1*time.Hour, OptimisticTimeoutPolicy)
+
+		// Act
+		_, err := policy(
+			context.Background(),
+			func(ctx context.Context, s string) (int, error) {
+				return 0, context.DeadlineExceeded
+			},
+			"foo",
+		)
+
+		// Assert
+		var timeoutErr *TimeoutError
+		if errors.As(err, &timeoutErr) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fail()
+		}
+	})
+
+	t.Run("should reject by timeout when parent context is cancelled", func(t *testing.T) {
+		// Arrange
+		policy := NewTimeoutPolicy[string, int](1*time.Hour, PessimisticTimeoutPolicy)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		// Act
+		_, err := policy(
+			ctx,
+			func(ctx context.Context, s string) (int, error) {
+				time.Sleep(1 * time.Hour)
+				return 0, nil
+			},
+			"foo",
+		)
+
+		// Assert
+		if !errors.Is(err, ErrTimeoutRejected) {
+			t.Fail()
+		}
+	})
+}
+
+func TestPessimisticTimeoutGracePeriod(t *testing.T) {
+	t.Run("should deliver outcome when call returns within grace period", func(t *testing.T) {
+		// Arrange
+		var cancelled bool
+		timeout := NewPessimisticTimeout[string, int](PessimisticTimeoutOptions[string, int]{
+			Timeout:     10 * time.Millisecond,
+			GracePeriod: 1 * time.Second,
+		})
+
+		// Act
+		v, err := timeout.Policy()(
+			context.Background(),
+			func(ctx context.Context, s string) (int, error) {
+				<-ctx.Done()
+				cancelled = true
+				time.Sleep(20 * time.Millisecond) // cleanup
+				return len(s), nil
+			},
+			"foo",
+		)
+
+		// Assert
+		if v != 3 || err != nil || !cancelled || timeout.Abandoned() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should abandon call when grace period expires", func(t *testing.T) {
+		// Arrange
+		timeout := NewPessimisticTimeout[string, int](PessimisticTimeoutOptions[string, int]{
+			Timeout:     10 * time.Millisecond,
+			GracePeriod: 20 * time.Millisecond,
+		})
+		release := make(chan struct{})
+		defer close(release)
+		start := time.Now()
+
+		// Act
+		_, err := timeout.Policy()(
+			context.Background(),
+			func(ctx context.Context, s string) (int, error) {
+				<-release
+				return len(s), nil
+			},
+			"foo",
+		)
+		elapsed := time.Since(start)
+
+		// Assert
+		if !errors.Is(err, ErrTimeoutRejected) || elapsed < 30*time.Millisecond || timeout.Abandoned() != 1 {
+			t.Fail()
+		}
+	})
+
+	t.Run("should report overrun since timeout including grace period", func(t *testing.T) {
+		// Arrange
+		overruns := make(chan time.Duration, 1)
+		timeout := NewPessimisticTimeout[string, int](PessimisticTimeoutOptions[string, int]{
+			Timeout:     10 * time.Millisecond,
+			GracePeriod: 30 * time.Millisecond,
+			OnAbandoned: func(_ int, _ error, overrun time.Duration) {
+				overruns <- overrun
+			},
+		})
+		release := make(chan struct{})
+
+		// Act
+		_, err := timeout.Policy()(
+			context.Background(),
+			func(ctx context.Context, s string) (int, error) {
+				<-release
+				return len(s), nil
+			},
+			"foo",
+		)
+		close(release)
+		overrun := <-overruns
+
+		// Assert
+		if !errors.Is(err, ErrTimeoutRejected) || overrun < 30*time.Millisecond {
+			t.Fail()
+		}
+	})
+}
@@ -1,6 +1,9 @@
 package resilience
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Policy[S, T any] func(context.Context, func(context.Context, S) (T, error), S) (T, error)
 type PolicyFunc[S, T any] func(context.Context, S) (T, error)
@@ -14,3 +17,15 @@ func (p Policy[S, T]) Bind(f PolicyFunc[S, T]) PolicyFunc[S, T] {
 func RejectOnError[T any](_ T, err error) bool {
 	return err == nil
 }
+
+type KeyFunc[S any, K comparable] func(context.Context, S) K
+
+type TimeProvider interface {
+	UtcNow() time.Time
+}
+
+// Scheduler may be implemented by a TimeProvider to take over scheduling of
+// delayed work, so that the work follows the same clock
+type Scheduler interface {
+	AfterFunc(d time.Duration, f func())
+}